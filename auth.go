@@ -0,0 +1,297 @@
+package frisby
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthProvider supplies the bearer tokens added to requests by Send()
+//
+// Set it with Global.SetAuth() or Frisby.SetAuth(). Providers are
+// shared by the Frisby objects using them, and cache their token.
+type AuthProvider interface {
+	// Token returns the cached token, or fetches one with client when there
+	// is none, it expired, or refresh is true, as after a 401 response
+	Token(client *http.Client, refresh bool) (*Token, error)
+}
+
+// Token is an access token, with its expiry when the server gave one
+type Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+// tokens are refreshed this long before they expire
+const tokenExpiryDelta = 10 * time.Second
+
+// Valid reports whether the token is set and not about to expire
+func (T *Token) Valid() bool {
+	return T != nil && T.AccessToken != "" &&
+		(T.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(T.Expiry))
+}
+
+// header returns the Authorization header value of the token
+func (T *Token) header() string {
+	if T.TokenType == "" || strings.EqualFold(T.TokenType, "bearer") {
+		return "Bearer " + T.AccessToken
+	}
+	return T.TokenType + " " + T.AccessToken
+}
+
+type bearerToken struct {
+	token *Token
+}
+
+// BearerToken returns an AuthProvider of a static token
+func BearerToken(token string) AuthProvider {
+	return &bearerToken{&Token{AccessToken: token}}
+}
+
+func (B *bearerToken) Token(client *http.Client, refresh bool) (*Token, error) {
+	return B.token, nil
+}
+
+// OAuth2 is an AuthProvider fetching tokens from an OAuth2 token endpoint
+//
+// Grant is "client_credentials", "password" or "refresh_token". When a
+// token response has a refresh token, it is used to refresh the token
+// before falling back to the Grant.
+type OAuth2 struct {
+	TokenUrl     string
+	ClientId     string
+	ClientSecret string
+	Scopes       []string
+	Grant        string
+
+	// for the password grant
+	Username string
+	Password string
+
+	// for the refresh_token grant, updated by token responses
+	RefreshToken string
+
+	// extra form values of token requests, like an audience
+	Params map[string]string
+
+	// send the client credentials in the form instead of with BasicAuth
+	ClientAuthInBody bool
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// ClientCredentials returns an OAuth2 provider using the client credentials grant
+func ClientCredentials(token_url, client_id, client_secret string, scopes ...string) *OAuth2 {
+	return &OAuth2{
+		TokenUrl:     token_url,
+		ClientId:     client_id,
+		ClientSecret: client_secret,
+		Scopes:       scopes,
+		Grant:        "client_credentials",
+	}
+}
+
+// PasswordGrant returns an OAuth2 provider using the resource owner password grant
+func PasswordGrant(token_url, client_id, client_secret, username, password string, scopes ...string) *OAuth2 {
+	return &OAuth2{
+		TokenUrl:     token_url,
+		ClientId:     client_id,
+		ClientSecret: client_secret,
+		Scopes:       scopes,
+		Grant:        "password",
+		Username:     username,
+		Password:     password,
+	}
+}
+
+// RefreshTokenGrant returns an OAuth2 provider using the refresh token grant
+func RefreshTokenGrant(token_url, client_id, client_secret, refresh_token string, scopes ...string) *OAuth2 {
+	return &OAuth2{
+		TokenUrl:     token_url,
+		ClientId:     client_id,
+		ClientSecret: client_secret,
+		Scopes:       scopes,
+		Grant:        "refresh_token",
+		RefreshToken: refresh_token,
+	}
+}
+
+func (O *OAuth2) Token(client *http.Client, refresh bool) (*Token, error) {
+	O.mu.Lock()
+	defer O.mu.Unlock()
+	if !refresh && O.token.Valid() {
+		return O.token, nil
+	}
+
+	var token *Token
+	var err error
+	if O.RefreshToken != "" {
+		token, err = O.fetch(client, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {O.RefreshToken}})
+	}
+	if token == nil && O.Grant != "refresh_token" {
+		values := url.Values{"grant_type": {O.Grant}}
+		if O.Grant == "password" {
+			values.Set("username", O.Username)
+			values.Set("password", O.Password)
+		}
+		token, err = O.fetch(client, values)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, fmt.Errorf("oauth2 refresh_token grant without a refresh token")
+	}
+
+	O.token = token
+	if token.RefreshToken != "" {
+		O.RefreshToken = token.RefreshToken
+	}
+	return token, nil
+}
+
+// fetch requests a token with the given grant values
+func (O *OAuth2) fetch(client *http.Client, values url.Values) (*Token, error) {
+	if len(O.Scopes) > 0 {
+		values.Set("scope", strings.Join(O.Scopes, " "))
+	}
+	for key, value := range O.Params {
+		values.Set(key, value)
+	}
+	if O.ClientAuthInBody {
+		values.Set("client_id", O.ClientId)
+		values.Set("client_secret", O.ClientSecret)
+	}
+
+	req, err := http.NewRequest("POST", O.TokenUrl, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !O.ClientAuthInBody {
+		req.SetBasicAuth(url.QueryEscape(O.ClientId), url.QueryEscape(O.ClientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2 %s token request failed: %v", values.Get("grant_type"), err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		RefreshToken     string      `json:"refresh_token"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	json.Unmarshal(body, &data)
+	if resp.StatusCode != 200 || data.AccessToken == "" {
+		reason := data.Error
+		if data.ErrorDescription != "" {
+			reason += ": " + data.ErrorDescription
+		}
+		if reason == "" {
+			reason = strings.TrimSpace(string(body))
+		}
+		return nil, fmt.Errorf("oauth2 %s token request failed with status %d: %s", values.Get("grant_type"), resp.StatusCode, reason)
+	}
+
+	token := &Token{AccessToken: data.AccessToken, TokenType: data.TokenType, RefreshToken: data.RefreshToken}
+	if seconds, err := data.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		token.Expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return token, nil
+}
+
+// authTransport adds the Authorization header of an AuthProvider
+// to requests, and retries once with a refreshed token after a 401
+type authTransport struct {
+	provider AuthProvider
+	next     http.RoundTripper
+
+	// sends the token requests of the provider
+	client *http.Client
+}
+
+func (A *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := A.provider.Token(A.client, false)
+	if err != nil {
+		return nil, err
+	}
+
+	next := A.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(A.authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// the body can not be sent again
+		return resp, nil
+	}
+
+	refreshed, err := A.provider.Token(A.client, true)
+	if err != nil || refreshed.AccessToken == token.AccessToken {
+		return resp, nil
+	}
+	retry := A.authorize(req, refreshed)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	return next.RoundTrip(retry)
+}
+
+// authorize returns a copy of req with the Authorization header of token
+func (A *authTransport) authorize(req *http.Request, token *Token) *http.Request {
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", token.header())
+	return authorized
+}
+
+// authorize installs the Auth provider on the client of the Frisby object,
+// fetching tokens with base, and returns a function restoring the client
+func (F *Frisby) authorize(base http.RoundTripper) func() {
+	client := &http.Client{Transport: base, Timeout: F.Req.Client.Timeout}
+	if F.Cassette != nil {
+		client.Transport = &cassetteTransport{cassette: F.Cassette, next: base}
+	}
+	return F.wrapTransport(func(next http.RoundTripper) http.RoundTripper {
+		return &authTransport{provider: F.Auth, next: next, client: client}
+	})
+}
+
+// Set the AuthProvider adding bearer tokens to the coming requests
+//
+// Frisby objects created after this call use it, and share its tokens
+func (G *Suite) SetAuth(provider AuthProvider) *Suite {
+	G.Auth = provider
+	return G
+}
+
+// Set the AuthProvider adding a bearer token to the coming request
+//
+// Set it to nil to send the request without the Suite provider
+func (F *Frisby) SetAuth(provider AuthProvider) *Frisby {
+	F.Auth = provider
+	return F
+}
@@ -0,0 +1,130 @@
+package frisby_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+// oauthServer issues tokens at /token and only serves /api with the last one
+type oauthServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	expiresIn int
+	issued    int
+	grants    []string
+	current   string
+}
+
+func newOAuthServer(expires_in int) *oauthServer {
+	S := &oauthServer{expiresIn: expires_in}
+	S.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		S.mu.Lock()
+		defer S.mu.Unlock()
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			id, secret, _ := r.BasicAuth()
+			if id != "client" || secret != "s3cret" {
+				w.WriteHeader(401)
+				fmt.Fprint(w, `{"error": "invalid_client", "error_description": "bad credentials"}`)
+				return
+			}
+			grant := r.Form.Get("grant_type")
+			if grant == "password" {
+				grant += ":" + r.Form.Get("username") + ":" + r.Form.Get("password")
+			}
+			S.grants = append(S.grants, grant+":"+r.Form.Get("scope"))
+			S.issued++
+			S.current = fmt.Sprintf("token-%d", S.issued)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  S.current,
+				"token_type":    "bearer",
+				"expires_in":    S.expiresIn,
+				"refresh_token": fmt.Sprintf("refresh-%d", S.issued),
+			})
+		default:
+			if r.Header.Get("Authorization") != "Bearer "+S.current {
+				w.WriteHeader(401)
+				return
+			}
+			fmt.Fprintf(w, `{"token": %q}`, S.current)
+		}
+	}))
+	return S
+}
+
+// revoke invalidates the last token
+func (S *oauthServer) revoke() {
+	S.mu.Lock()
+	defer S.mu.Unlock()
+	S.current = "revoked"
+}
+
+func TestBearerToken(t *testing.T) {
+	ts := newEchoServer()
+	defer ts.Close()
+
+	frisby.CreateT(t, "Test BearerToken").
+		SetAuth(frisby.BearerToken("abc")).
+		Get(ts.URL).
+		Send().
+		ExpectJson("auth", "Bearer abc")
+}
+
+func TestClientCredentials(t *testing.T) {
+	ts := newOAuthServer(3600)
+	defer ts.Close()
+
+	G := frisby.NewSuite("Test ClientCredentials")
+	G.PrintProgressDot = false
+	G.SetAuth(frisby.ClientCredentials(ts.URL+"/token", "client", "s3cret", "read", "write"))
+
+	G.CreateT(t, "Test ClientCredentials first").Get(ts.URL+"/api").Send().ExpectStatus(200).ExpectJson("token", "token-1")
+	G.CreateT(t, "Test ClientCredentials cached").Post(ts.URL+"/api").SetJson(map[string]int{"a": 1}).Send().ExpectJson("token", "token-1")
+
+	ts.revoke()
+	G.CreateT(t, "Test ClientCredentials refreshed").Post(ts.URL+"/api").SetJson(map[string]int{"a": 1}).Send().ExpectStatus(200).ExpectJson("token", "token-2")
+
+	expected := []string{"client_credentials:read write", "refresh_token:read write"}
+	if strings.Join(ts.grants, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected the grants %q, but got %q", expected, ts.grants)
+	}
+}
+
+func TestOAuth2Expiry(t *testing.T) {
+	// tokens expiring within a few seconds are refreshed before use
+	ts := newOAuthServer(5)
+	defer ts.Close()
+
+	auth := frisby.PasswordGrant(ts.URL+"/token", "client", "s3cret", "alice", "pw")
+	for i := 1; i <= 2; i++ {
+		frisby.CreateT(t, "Test OAuth2 expiry").SetAuth(auth).Get(ts.URL+"/api").Send().ExpectJson("token", fmt.Sprintf("token-%d", i))
+	}
+
+	expected := []string{"password:alice:pw:", "refresh_token:"}
+	if strings.Join(ts.grants, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected the grants %q, but got %q", expected, ts.grants)
+	}
+}
+
+func TestOAuth2Error(t *testing.T) {
+	ts := newOAuthServer(3600)
+	defer ts.Close()
+
+	F := frisby.Create("Test OAuth2 error").
+		SetAuth(frisby.ClientCredentials(ts.URL+"/token", "client", "wrong")).
+		Get(ts.URL + "/api").
+		Send()
+
+	if F.Error() == nil || !strings.Contains(F.Error().Error(), "invalid_client: bad credentials") {
+		t.Errorf("Expected the token request to fail, but got %v", F.Error())
+	}
+}
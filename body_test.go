@@ -0,0 +1,163 @@
+package frisby_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+// newMethodServer echoes the method, Content-Type and body of requests
+func newMethodServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"method":       r.Method,
+			"content_type": r.Header.Get("Content-Type"),
+			"body":         string(body),
+		})
+	}))
+}
+
+func TestDo(t *testing.T) {
+	ts := newMethodServer()
+	defer ts.Close()
+
+	frisby.CreateT(t, "Test Do PROPFIND").
+		Do("PROPFIND", ts.URL+"/dav/").
+		SetHeader("Depth", "1").
+		SetBodyString("application/xml", `<propfind xmlns="DAV:"><allprop/></propfind>`).
+		Send().
+		ExpectStatus(200).
+		ExpectJson("method", "PROPFIND").
+		ExpectJson("content_type", "application/xml").
+		ExpectJson("body", `<propfind xmlns="DAV:"><allprop/></propfind>`)
+
+	frisby.CreateT(t, "Test Do PURGE").
+		Do("PURGE", ts.URL+"/cache/item").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("method", "PURGE").
+		ExpectJson("body", "")
+}
+
+func TestDoProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"method": r.Method,
+			"url":    r.RequestURI,
+		})
+	}))
+	defer proxy.Close()
+
+	frisby.CreateT(t, "Test Do through a proxy").
+		Do("PURGE", "http://api.test/cache/item").
+		SetProxy(proxy.URL).
+		SetParam("all", "1").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("method", "PURGE").
+		ExpectJson("url", "http://api.test/cache/item?all=1")
+}
+
+func TestSetBody(t *testing.T) {
+	ts := newMethodServer()
+	defer ts.Close()
+
+	F := frisby.CreateT(t, "Test SetBody").
+		Post(ts.URL).
+		SetHeader("Content-Type", "application/octet-stream").
+		SetBody([]byte{0x08, 0x2a, 0x12, 0x00}).
+		Send().
+		ExpectJson("content_type", "application/octet-stream").
+		ExpectJson("body", "\x08\x2a\x12\x00")
+
+	// the body is sent again by the next Send()
+	F.Send().ExpectJson("body", "\x08\x2a\x12\x00")
+
+	frisby.CreateT(t, "Test SetBodyReader").
+		Put(ts.URL).
+		SetBodyReader(strings.NewReader("streamed text")).
+		Send().
+		ExpectJson("body", "streamed text")
+
+	G := frisby.NewSuite("Test SetBody")
+	G.PrintProgressDot = false
+	G.SetJson(map[string]int{"id": 1})
+	G.SetVar("id", 42)
+
+	G.CreateT(t, "Test SetBodyString").
+		Post(ts.URL).
+		SetBodyString("text/plain", "order {{id}}").
+		Send().
+		ExpectJson("content_type", "text/plain").
+		ExpectJson("body", "order 42")
+
+	G.CreateT(t, "Test SetJson after SetBody").
+		Post(ts.URL).
+		SetBody([]byte("raw")).
+		SetJson(map[string]int{"id": 2}).
+		Send().
+		ExpectJson("content_type", "application/json; charset=utf-8").
+		ExpectJson("body", `{"id":2}`)
+}
+
+func TestRawBodyAsCurl(t *testing.T) {
+	F := frisby.Create("Test raw body").
+		Do("REPORT", "http://api.test/dav").
+		SetBodyString("application/xml", "<report/>")
+	expected := "curl -X REPORT http://api.test/dav \\\n" +
+		"  -H 'Content-Type: application/xml' \\\n" +
+		"  --data-raw '<report/>'"
+	if curl := F.AsCurl(); curl != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, curl)
+	}
+
+	F = frisby.Create("Test reader body").Post("http://api.test/upload").SetBodyReader(strings.NewReader("data"))
+	expected = "curl http://api.test/upload \\\n  --data-binary @-"
+	if curl := F.AsCurl(); curl != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, curl)
+	}
+}
+
+func TestSpecBody(t *testing.T) {
+	ts := newMethodServer()
+	defer ts.Close()
+
+	spec, err := frisby.ParseSpec([]byte(`
+tests:
+  - name: lock
+    method: LOCK
+    url: ` + ts.URL + `/dav/file
+    headers: {Content-Type: application/xml}
+    body: <lockinfo/>
+    expect:
+      json: {method: LOCK, body: <lockinfo/>}
+  - name: lowercase standard method
+    method: post
+    url: ` + ts.URL + `/items
+    body: item
+    expect:
+      json: {method: POST, body: item}
+  - name: case-sensitive method
+    method: purge
+    url: ` + ts.URL + `/items
+    expect:
+      json: {method: purge}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	G := frisby.NewSuite("Test Spec body")
+	G.PrintProgressDot = false
+	for _, F := range spec.RunSuite(G) {
+		if len(F.Errs) > 0 {
+			t.Errorf("Expected %q to pass, but got %v", F.Name, F.Errs)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package frisby
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Budget limits the ExecutionTime of the Frisby objects of a Suite
+// which have the Tag, or of all of them when Tag is empty
+//
+// With a Percentile between 0 and 100 the nearest rank percentile of
+// their times is checked against Max, otherwise their total time is.
+type Budget struct {
+	Tag        string
+	Percentile float64
+	Max        time.Duration
+}
+
+func (B Budget) String() string {
+	tag := "every request"
+	if B.Tag != "" {
+		tag = fmt.Sprintf("%q requests", B.Tag)
+	}
+	if B.Percentile > 0 {
+		return fmt.Sprintf("p%g time of %s", B.Percentile, tag)
+	}
+	return fmt.Sprintf("total time of %s", tag)
+}
+
+// Add tags to the Frisby object, to check it against the Suite budgets
+func (F *Frisby) Tag(tags ...string) *Frisby {
+	F.Tags = append(F.Tags, tags...)
+	return F
+}
+
+// HasTag reports whether the Frisby object has the tag
+func (F *Frisby) HasTag(tag string) bool {
+	for _, t := range F.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks the request took less than max, as measured by ExecutionTime
+func (F *Frisby) ExpectResponseTimeUnder(max time.Duration) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	actual := time.Duration(F.ExecutionTime * float64(time.Second))
+	err_str := ""
+	if actual >= max {
+		err_str = fmt.Sprintf("Expected response time under %v, but took %v", max, actual)
+	}
+	F.addAssertion("response_time", "", max.Seconds(), F.ExecutionTime, err_str)
+	return F
+}
+
+// Set a budget for the total ExecutionTime of the Frisby objects with the tag,
+// or of every Frisby object when tag is empty
+func (G *Suite) SetTotalBudget(tag string, max time.Duration) *Suite {
+	return G.AddBudget(Budget{Tag: tag, Max: max})
+}
+
+// Set a budget for a percentile, like 99, of the ExecutionTimes of the
+// Frisby objects with the tag, or of every Frisby object when tag is empty
+func (G *Suite) SetPercentileBudget(tag string, percentile float64, max time.Duration) *Suite {
+	if !(percentile > 0 && percentile <= 100) {
+		G.AddError(G.Name, fmt.Sprintf("Budget percentile %g is not in (0, 100]", percentile))
+		return G
+	}
+	return G.AddBudget(Budget{Tag: tag, Percentile: percentile, Max: max})
+}
+
+// Add a Budget, which is checked by PrintReport(), the JSON reports
+// and CheckBudgets()
+//
+// The Suite keeps the Frisby objects created after it, to check them.
+// A Percentile above 100, or below 0, is an error of the Suite.
+func (G *Suite) AddBudget(budget Budget) *Suite {
+	if !(budget.Percentile >= 0 && budget.Percentile <= 100) {
+		G.AddError(G.Name, fmt.Sprintf("Budget percentile %g is not between 0 and 100", budget.Percentile))
+		return G
+	}
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	G.Budgets = append(G.Budgets, budget)
+	return G
+}
+
+// CheckBudgets returns an error for every Budget exceeded by the Frisby objects
+//
+// Budgets without any matching Frisby object which sent a request pass.
+func (G *Suite) CheckBudgets() []error {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	return G.checkBudgets()
+}
+
+func (G *Suite) checkBudgets() []error {
+	errs := make([]error, 0)
+	for _, budget := range G.Budgets {
+		times := make([]float64, 0)
+		for _, F := range G.Frisbies {
+			if F.Resp == nil && F.ExecutionTime == 0 {
+				continue
+			}
+			if budget.Tag == "" || F.HasTag(budget.Tag) {
+				times = append(times, F.ExecutionTime)
+			}
+		}
+		if len(times) == 0 {
+			continue
+		}
+
+		var seconds float64
+		if budget.Percentile > 0 {
+			sort.Float64s(times)
+			seconds = percentile(times, budget.Percentile)
+		} else {
+			for _, t := range times {
+				seconds += t
+			}
+		}
+		actual := time.Duration(seconds * float64(time.Second))
+		if actual > budget.Max {
+			errs = append(errs, fmt.Errorf("Expected the %s to be at most %v, but it was %v over %d requests",
+				budget, budget.Max, actual, len(times)))
+		}
+	}
+	return errs
+}
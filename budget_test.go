@@ -0,0 +1,82 @@
+package frisby_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestExpectResponseTimeUnder(t *testing.T) {
+	ts := newSlowServer()
+	ts.Start()
+	defer ts.Close()
+
+	frisby.CreateT(t, "Test ExpectResponseTimeUnder").
+		Get(ts.URL).
+		Send().
+		ExpectResponseTimeUnder(time.Second)
+
+	F := frisby.Create("Test ExpectResponseTimeUnder fails").
+		Get(ts.URL).
+		Send().
+		ExpectResponseTimeUnder(10 * time.Millisecond)
+	if len(F.Errs) != 1 {
+		t.Errorf("Expected 1 error, but got %v", F.Errs)
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	slow := newSlowServer()
+	slow.Start()
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	suite := frisby.NewSuite("Test Budgets").
+		SetTotalBudget("", 10*time.Second).
+		SetPercentileBudget("checkout", 50, 40*time.Millisecond).
+		SetPercentileBudget("search", 75, 40*time.Millisecond).
+		SetTotalBudget("missing", time.Nanosecond)
+
+	for i := 0; i < 3; i++ {
+		suite.Create("Test Budgets search").Tag("search").Get(fast.URL).Send()
+	}
+	suite.Create("Test Budgets checkout").Tag("checkout").Get(fast.URL).Send()
+	suite.Create("Test Budgets checkout").Tag("checkout").Get(slow.URL).Send()
+
+	errs := suite.CheckBudgets()
+	if len(errs) != 0 {
+		t.Errorf("Expected no exceeded budgets, but got %v", errs)
+	}
+	if !suite.CheckBudgetsT(t) {
+		t.Errorf("Expected CheckBudgetsT to pass")
+	}
+
+	suite.Create("Test Budgets checkout").Tag("checkout", "search").Get(slow.URL).Send()
+
+	errs = suite.CheckBudgets()
+	if len(errs) != 1 {
+		t.Fatalf("Expected the checkout budget to be exceeded, but got %v", errs)
+	}
+	expected := `Expected the p50 time of "checkout" requests to be at most 40ms`
+	if msg := errs[0].Error(); len(msg) < len(expected) || msg[:len(expected)] != expected {
+		t.Errorf("Expected %q, but got %q", expected, msg)
+	}
+	if summary := suite.Summary(); summary.Passed || len(summary.Budgets) != 1 {
+		t.Errorf("Expected the summary to fail on the budget, but got %+v", summary)
+	}
+}
+
+func TestBudgetPercentileRange(t *testing.T) {
+	suite := frisby.NewSuite("Test Budget percentile range").
+		SetPercentileBudget("", 150, time.Second).
+		SetPercentileBudget("", 0, time.Second).
+		AddBudget(frisby.Budget{Percentile: -1, Max: time.Second})
+
+	if len(suite.Budgets) != 0 || len(suite.Errs[suite.Name]) != 3 {
+		t.Errorf("Expected 3 errors and no budgets, but got %v and %v", suite.Errs, suite.Budgets)
+	}
+}
@@ -0,0 +1,146 @@
+package frisby
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// matches {{name}} and {{ name }} placeholders
+var varPattern = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// Capture stores the value of the response JSON at path
+// into the Global variable store under name
+//
+// path can be a dot joined field names, a JSON Pointer or a JSONPath,
+// see FindJson() for details. If path matches several values,
+// they are all stored as a []interface{}.
+// ex:  'path.to.subobject.field'
+//
+// Stored variables can be used as {{name}} in the URL, Headers,
+// Cookies, Params, Form data and JSON body of later requests.
+func (F *Frisby) Capture(name, path string) *Frisby {
+	simp_json, err := F.Resp.Json()
+	if err != nil {
+		F.AddError(err.Error())
+		return F
+	}
+	matches, err := findJson(simp_json.Interface(), path, F.Suite.PathSeparator)
+	if err != nil {
+		F.AddError(err.Error())
+		return F
+	}
+
+	value := jsonActual(matches)
+	if len(matches) == 0 || value == nil {
+		err_str := fmt.Sprintf("Capture %q failed, Json %q was missing", name, path)
+		F.AddError(err_str)
+		return F
+	}
+
+	F.Suite.SetVar(name, value)
+	return F
+}
+
+// CaptureHeader stores the value of the response Header key
+// into the Global variable store under name
+func (F *Frisby) CaptureHeader(name, key string) *Frisby {
+	value := F.Resp.Header.Get(key)
+	if value == "" {
+		err_str := fmt.Sprintf("Capture %q failed, Header %q was missing", name, key)
+		F.AddError(err_str)
+		return F
+	}
+
+	F.Suite.SetVar(name, value)
+	return F
+}
+
+// CaptureCookie stores the value of the response Cookie key
+// into the Global variable store under name
+func (F *Frisby) CaptureCookie(name, key string) *Frisby {
+	for _, cookie := range F.Resp.Cookies() {
+		if cookie.Name == key {
+			F.Suite.SetVar(name, cookie.Value)
+			return F
+		}
+	}
+
+	err_str := fmt.Sprintf("Capture %q failed, Cookie %q was missing", name, key)
+	F.AddError(err_str)
+	return F
+}
+
+// interpolate replaces the {{name}} placeholders in the coming request
+// with values from the Global variable store
+func (F *Frisby) interpolate() {
+	F.Url = F.interpolateString(F.Url)
+	F.interpolateMap(F.Req.Headers)
+	F.interpolateMap(F.Req.Cookies)
+	F.interpolateMap(F.Req.Params)
+	F.interpolateMap(F.Req.Data)
+
+	if F.Req.Json != nil {
+		F.Req.Json = F.interpolateJson(F.Req.Json)
+	}
+	if F.bodyText {
+		F.body = []byte(F.interpolateString(string(F.body)))
+	}
+}
+
+func (F *Frisby) interpolateMap(values map[string]string) {
+	for key, value := range values {
+		values[key] = F.interpolateString(value)
+	}
+}
+
+func (F *Frisby) interpolateString(str string) string {
+	if !strings.Contains(str, "{{") {
+		return str
+	}
+	return varPattern.ReplaceAllStringFunc(str, func(match string) string {
+		name := varPattern.FindStringSubmatch(match)[1]
+		value, ok := F.Suite.GetVar(name)
+		if !ok {
+			F.AddError(fmt.Sprintf("Unknown variable %q", name))
+			return match
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// interpolateJson replaces placeholders in the string values of
+// the JSON body, which may be any value encoding/json can handle
+func (F *Frisby) interpolateJson(body interface{}) interface{} {
+	data, err := json.Marshal(body)
+	if err != nil || !strings.Contains(string(data), "{{") {
+		return body
+	}
+
+	// numbers are kept as json.Number, so large integers keep their precision
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return body
+	}
+	return F.interpolateValue(generic)
+}
+
+func (F *Frisby) interpolateValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case string:
+		return F.interpolateString(val)
+	case map[string]interface{}:
+		for key, item := range val {
+			val[key] = F.interpolateValue(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = F.interpolateValue(item)
+		}
+	}
+	return value
+}
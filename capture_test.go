@@ -0,0 +1,66 @@
+package frisby_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func newEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.ParseForm()
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "cookie-value"})
+		w.Header().Set("X-Request-Id", "header-value")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":    map[string]interface{}{"auth": map[string]interface{}{"token": "secret"}},
+			"path":    r.URL.Path,
+			"query":   r.URL.Query().Get("q"),
+			"auth":    r.Header.Get("Authorization"),
+			"session": r.Header.Get("Cookie"),
+			"body":    string(body),
+		})
+	}))
+}
+
+func TestCapture(t *testing.T) {
+	ts := newEchoServer()
+	defer ts.Close()
+
+	frisby.CreateT(t, "Test Capture").
+		Post(ts.URL+"/login").
+		Send().
+		ExpectStatus(200).
+		Capture("token", "data.auth.token").
+		CaptureHeader("request", "X-Request-Id").
+		CaptureCookie("session", "session")
+
+	for name, expected := range map[string]string{
+		"token":   "secret",
+		"request": "header-value",
+		"session": "cookie-value",
+	} {
+		value, ok := frisby.Global.GetVar(name)
+		if !ok || value != expected {
+			t.Errorf("Expected variable %q to be %q, but got %v", name, expected, value)
+		}
+	}
+
+	frisby.CreateT(t, "Test Interpolate").
+		Post(ts.URL+"/users/{{request}}").
+		SetHeader("Authorization", "Bearer {{ token }}").
+		SetCookie("session", "{{session}}").
+		SetParam("q", "{{token}}").
+		SetJson(map[string]interface{}{"token": "{{token}}", "list": []string{"{{request}}"}, "id": int64(9007199254740993)}).
+		Send().
+		ExpectStatus(200).
+		ExpectJson("path", "/users/header-value").
+		ExpectJson("auth", "Bearer secret").
+		ExpectJson("session", "session=cookie-value").
+		ExpectJson("query", "secret").
+		ExpectJson("body", `{"id":9007199254740993,"list":["header-value"],"token":"secret"}`)
+}
@@ -0,0 +1,312 @@
+package frisby
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+type CassetteMode int
+
+const (
+	// ReplayMode serves responses from the cassette without network,
+	// requests which match no interaction fail
+	ReplayMode CassetteMode = iota
+	// RecordMode sends requests and stores every interaction in the cassette
+	RecordMode
+)
+
+// CassetteMatch selects the request fields compared when replaying
+type CassetteMatch struct {
+	Method bool
+	Url    bool
+	Body   bool
+	// header keys whose values must be equal
+	Headers []string
+}
+
+// DefaultCassetteMatch compares the method and the full URL, query included
+var DefaultCassetteMatch = CassetteMatch{Method: true, Url: true}
+
+// DefaultCassetteFilter is the header keys not written to cassettes:
+// cookies, and the credentials of Basic, Bearer, OAuth2, Digest and AWS SigV4 auth
+var DefaultCassetteFilter = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Authentication-Info",
+	"Cookie",
+	"Set-Cookie",
+	"X-Amz-Security-Token",
+	"X-Amz-Date",
+	"X-Amz-Content-Sha256",
+}
+
+// Cassette is a file of recorded request/response pairs
+//
+// Set it with Global.SetCassette() or Frisby.SetCassette() and Send()
+// records to it or replays from it, depending on its Mode.
+type Cassette struct {
+	Filename string        `json:"-"`
+	Mode     CassetteMode  `json:"-"`
+	Match    CassetteMatch `json:"-"`
+
+	// request and response header keys dropped when recording
+	Filter []string `json:"-"`
+	// called on each recorded interaction before it is saved,
+	// to redact other headers or secrets in the bodies
+	Redact func(I *Interaction) `json:"-"`
+
+	Interactions []*Interaction `json:"interactions"`
+
+	mu   sync.Mutex
+	used map[*Interaction]bool
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type CassetteRequest struct {
+	Method  string      `json:"method"`
+	Url     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	CassetteBody
+}
+
+type CassetteResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	CassetteBody
+}
+
+// CassetteBody is a body stored as text, or as base64
+// when it is not valid UTF-8
+type CassetteBody struct {
+	Body     string `json:"body,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// OpenCassette opens the cassette in the given file
+//
+// In ReplayMode the file must exist. In RecordMode it is created,
+// or truncated, on the first recorded interaction. The headers in
+// DefaultCassetteFilter are not recorded.
+func OpenCassette(filename string, mode CassetteMode) (*Cassette, error) {
+	C := &Cassette{
+		Filename: filename,
+		Mode:     mode,
+		Match:    DefaultCassetteMatch,
+		Filter:   DefaultCassetteFilter,
+		used:     make(map[*Interaction]bool),
+	}
+	if mode == RecordMode {
+		return C, nil
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, C); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return C, nil
+}
+
+// Save writes the interactions to the cassette file
+//
+// Send() saves after each recorded interaction, so this is
+// only needed when Interactions are changed by hand.
+func (C *Cassette) Save() error {
+	C.mu.Lock()
+	defer C.mu.Unlock()
+	return C.save()
+}
+
+func (C *Cassette) save() error {
+	data, err := json.MarshalIndent(C, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(C.Filename); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(C.Filename, append(data, '\n'), 0644)
+}
+
+// find returns the first interaction matching req which was not replayed yet,
+// or the first matching one when all of them were
+func (C *Cassette) find(req *http.Request, body []byte) *Interaction {
+	var replayed *Interaction
+	for _, I := range C.Interactions {
+		if !C.matches(I, req, body) {
+			continue
+		}
+		if !C.used[I] {
+			C.used[I] = true
+			return I
+		}
+		if replayed == nil {
+			replayed = I
+		}
+	}
+	return replayed
+}
+
+func (C *Cassette) matches(I *Interaction, req *http.Request, body []byte) bool {
+	if C.Match.Method && I.Request.Method != req.Method {
+		return false
+	}
+	if C.Match.Url && I.Request.Url != req.URL.String() {
+		return false
+	}
+	if C.Match.Body && !bytes.Equal(I.Request.bytes(), body) {
+		return false
+	}
+	for _, key := range C.Match.Headers {
+		if I.Request.Headers.Get(key) != req.Header.Get(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// roundTrip serves req from the cassette in ReplayMode,
+// or sends it through next and records it in RecordMode
+func (C *Cassette) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if C.Mode == ReplayMode {
+		C.mu.Lock()
+		I := C.find(req, body)
+		C.mu.Unlock()
+		if I == nil {
+			return nil, fmt.Errorf("cassette %s has no interaction matching %s %s", C.Filename, req.Method, req.URL)
+		}
+		return I.Response.response(req), nil
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(content))
+
+	I := &Interaction{
+		Request: CassetteRequest{
+			Method:       req.Method,
+			Url:          req.URL.String(),
+			Headers:      C.filter(req.Header),
+			CassetteBody: cassetteBody(body),
+		},
+		Response: CassetteResponse{
+			Status:       resp.StatusCode,
+			Headers:      C.filter(resp.Header),
+			CassetteBody: cassetteBody(content),
+		},
+	}
+	if C.Redact != nil {
+		C.Redact(I)
+	}
+
+	C.mu.Lock()
+	defer C.mu.Unlock()
+	C.Interactions = append(C.Interactions, I)
+	if err := C.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// filter returns a copy of header without the keys in Filter
+func (C *Cassette) filter(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range C.Filter {
+		header.Del(key)
+	}
+	return header
+}
+
+func cassetteBody(data []byte) CassetteBody {
+	if utf8.Valid(data) {
+		return CassetteBody{Body: string(data)}
+	}
+	return CassetteBody{Body: base64.StdEncoding.EncodeToString(data), Encoding: "base64"}
+}
+
+func (B CassetteBody) bytes() []byte {
+	if B.Encoding == "base64" {
+		data, _ := base64.StdEncoding.DecodeString(B.Body)
+		return data
+	}
+	return []byte(B.Body)
+}
+
+func (R CassetteResponse) response(req *http.Request) *http.Response {
+	body := R.bytes()
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", R.Status, http.StatusText(R.Status)),
+		StatusCode:    R.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        R.Headers.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// cassetteTransport routes the requests of a Frisby object through a Cassette
+type cassetteTransport struct {
+	cassette *Cassette
+	next     http.RoundTripper
+}
+
+func (T *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return T.cassette.roundTrip(T.next, req)
+}
+
+// Set the Cassette which requests are recorded to or replayed from
+//
+// Frisby objects created after this call use it in Send()
+func (G *Suite) SetCassette(cassette *Cassette) *Suite {
+	G.Cassette = cassette
+	return G
+}
+
+// Set the Cassette which the coming request is recorded to or replayed from
+//
+// Set it to nil to send the request without the Global cassette
+func (F *Frisby) SetCassette(cassette *Cassette) *Frisby {
+	F.Cassette = cassette
+	return F
+}
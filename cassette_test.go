@@ -0,0 +1,105 @@
+package frisby_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestCassette(t *testing.T) {
+	ts := newEchoServer()
+	filename := filepath.Join(t.TempDir(), "cassettes", "echo.json")
+
+	recorder, err := frisby.OpenCassette(filename, frisby.RecordMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frisby.CreateT(t, "Test Cassette record").
+		SetCassette(recorder).
+		Post(ts.URL+"/users").
+		SetJson(map[string]string{"name": "alice"}).
+		Send().
+		ExpectStatus(200).
+		ExpectJson("path", "/users")
+	frisby.CreateT(t, "Test Cassette record").
+		SetCassette(recorder).
+		Post(ts.URL + "/users").
+		SetJson(map[string]string{"name": "bob"}).
+		Send().
+		ExpectStatus(200)
+
+	if len(recorder.Interactions) != 2 {
+		t.Fatalf("Expected 2 interactions, but got %d", len(recorder.Interactions))
+	}
+
+	// replay without network
+	ts.Close()
+
+	player, err := frisby.OpenCassette(filename, frisby.ReplayMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player.Match.Body = true
+
+	frisby.CreateT(t, "Test Cassette replay").
+		SetCassette(player).
+		Post(ts.URL+"/users").
+		SetJson(map[string]string{"name": "bob"}).
+		Send().
+		ExpectStatus(200).
+		ExpectHeader("X-Request-Id", "header-value").
+		ExpectJson("body", `{"name":"bob"}`)
+
+	F := frisby.Create("Test Cassette unmatched").
+		SetCassette(player).
+		Post(ts.URL + "/users").
+		SetJson(map[string]string{"name": "carol"}).
+		Send()
+	if len(F.Errs) != 1 {
+		t.Errorf("Expected 1 error, but got %v", F.Errs)
+	}
+}
+
+func TestCassetteFilter(t *testing.T) {
+	ts := newEchoServer()
+	defer ts.Close()
+	filename := filepath.Join(t.TempDir(), "filter.json")
+
+	recorder, err := frisby.OpenCassette(filename, frisby.RecordMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Filter = append(recorder.Filter, "X-Api-Key")
+	recorder.Redact = func(I *frisby.Interaction) {
+		I.Response.Body = strings.Replace(I.Response.Body, "bearer-secret", "REDACTED", -1)
+		I.Response.Body = strings.Replace(I.Response.Body, "cookie-secret", "REDACTED", -1)
+	}
+
+	frisby.CreateT(t, "Test Cassette filter").
+		SetCassette(recorder).
+		Get(ts.URL+"/users").
+		SetHeader("Authorization", "Bearer bearer-secret").
+		SetHeader("X-Api-Key", "key-secret").
+		SetHeader("Accept", "application/json").
+		SetCookie("token", "cookie-secret").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("auth", "Bearer bearer-secret")
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"bearer-secret", "key-secret", "cookie-secret", "cookie-value"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected the cassette not to contain %q, but got\n%s", secret, data)
+		}
+	}
+	I := recorder.Interactions[0]
+	if I.Request.Headers.Get("Accept") != "application/json" || I.Response.Headers.Get("X-Request-Id") != "header-value" {
+		t.Errorf("Expected other headers to be recorded, but got %v and %v", I.Request.Headers, I.Response.Headers)
+	}
+}
@@ -0,0 +1,295 @@
+// frisby runs the requests and expectations described in YAML or JSON spec files
+//
+//	frisby [-report text|gotest|json|jsonl] [-junit report.xml] [-cassette file [-record]] [-har file] [-budget p99=300ms] spec.yaml [spec.json ...]
+//
+// Each spec file runs in its own Suite, so the global settings and vars
+// of one file do not apply to the others. It exits with a non-zero status
+// if any expectation failed or any response time budget was exceeded.
+//
+// With -import-postman or -import-curl it instead writes the YAML spec
+// of a Postman v2.1 collection, or of a file of curl commands separated
+// by blank lines, and any parts it could not convert to stderr.
+//
+//	frisby -import-postman collection.json > spec.yaml
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/verdverm/frisby"
+)
+
+var (
+	report = flag.String("report", "text", "report format, one of: text, gotest, json, jsonl")
+	junit  = flag.String("junit", "", "write a JUnit XML report to the given file")
+
+	cassette = flag.String("cassette", "", "replay the responses recorded in the given cassette file")
+	record   = flag.Bool("record", false, "send the requests and record them to the -cassette file")
+
+	har        = flag.String("har", "", "write every request and response to the given HAR file")
+	har_redact = flag.String("har-redact", "Authorization,Proxy-Authorization,Cookie,Set-Cookie", "comma separated headers redacted from the -har file")
+
+	budget = flag.String("budget", "", "comma separated response time budgets of each spec file, like p99=300ms,total=1m")
+
+	import_postman = flag.String("import-postman", "", "write the YAML spec of the given Postman v2.1 collection")
+	import_curl    = flag.String("import-curl", "", "write the YAML spec of the curl commands, separated by blank lines, in the given file")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: frisby [flags] spec.yaml [spec.json ...]\n\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *import_postman != "" || *import_curl != "" {
+		importSpec()
+		return
+	}
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	switch *report {
+	case "text", "gotest", "json", "jsonl":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown report format %q\n", *report)
+		os.Exit(2)
+	}
+
+	var cassette_file *frisby.Cassette
+	if *cassette != "" {
+		mode := frisby.ReplayMode
+		if *record {
+			mode = frisby.RecordMode
+		}
+		C, err := frisby.OpenCassette(*cassette, mode)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		cassette_file = C
+	} else if *record {
+		fmt.Fprintln(os.Stderr, "-record requires -cassette")
+		os.Exit(2)
+	}
+
+	var har_file *frisby.Har
+	if *har != "" {
+		har_file = frisby.NewHar(*har)
+		if *har_redact != "" {
+			har_file.Redact(frisby.RedactHeaders(strings.Split(*har_redact, ",")...))
+		}
+	}
+
+	budgets, err := parseBudgets(*budget)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	specs := make([]*frisby.Spec, 0, flag.NArg())
+	for _, filename := range flag.Args() {
+		spec, err := frisby.LoadSpec(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		specs = append(specs, spec)
+	}
+
+	junit_report := frisby.NewJUnitReport("frisby")
+	json_report := &frisby.JsonReport{Frisbies: make([]frisby.FrisbyReport, 0)}
+
+	// each spec runs in its own suite, so its global settings
+	// and vars do not leak into the following specs
+	failed := false
+	for _, spec := range specs {
+		suite := frisby.NewSuite(spec.Name)
+		suite.PrintProgressDot = false
+		suite.KeepFrisbies = true
+		if *report == "jsonl" {
+			suite.StreamJsonLines(os.Stdout)
+		}
+		if cassette_file != nil {
+			suite.SetCassette(cassette_file)
+		}
+		if har_file != nil {
+			suite.SetHar(har_file)
+		}
+		for _, B := range budgets {
+			suite.AddBudget(B)
+		}
+
+		frisbies := spec.RunSuite(suite)
+		junit_report.AddSuite(spec.Name, frisbies, suite.JUnitProperties()...)
+		for _, F := range frisbies {
+			switch *report {
+			case "text":
+				F.PrintReport()
+			case "gotest":
+				F.PrintGoTestReport()
+			}
+		}
+
+		suite_report := suite.JsonReport()
+		json_report.Frisbies = append(json_report.Frisbies, suite_report.Frisbies...)
+		addSummary(&json_report.Summary, suite_report.Summary)
+		if !suite_report.Summary.Passed {
+			failed = true
+		}
+		if *report == "gotest" {
+			for _, err_str := range suite_report.Summary.Budgets {
+				fmt.Printf("--- FAIL: %s budgets\n\t %s\n", spec.Name, err_str)
+			}
+		}
+		if *report == "text" {
+			fmt.Printf("\n%s", spec.Name)
+			suite.PrintReport()
+		}
+	}
+	json_report.Summary.Passed = !failed
+
+	switch *report {
+	case "gotest":
+		if failed {
+			fmt.Println("FAIL")
+		} else {
+			fmt.Println("PASS")
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(json_report)
+	case "jsonl":
+		json.NewEncoder(os.Stdout).Encode(struct {
+			Event string `json:"event"`
+			frisby.JsonSummary
+		}{"summary", json_report.Summary})
+	}
+
+	if har_file != nil {
+		if err := har_file.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	if *junit != "" {
+		if err := junit_report.WriteFile(*junit); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// parseBudgets parses comma separated budgets on every request of a spec,
+// like p99=300ms for a percentile or total=1m for the total time
+func parseBudgets(s string) ([]frisby.Budget, error) {
+	budgets := make([]frisby.Budget, 0)
+	if s == "" {
+		return budgets, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid budget %q, expected pNN=duration or total=duration", part)
+		}
+		max, err := time.ParseDuration(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid budget %q: %v", part, err)
+		}
+
+		B := frisby.Budget{Max: max}
+		if kv[0] != "total" {
+			if !strings.HasPrefix(kv[0], "p") {
+				return nil, fmt.Errorf("invalid budget %q, expected pNN=duration or total=duration", part)
+			}
+			B.Percentile, err = strconv.ParseFloat(kv[0][1:], 64)
+			if err != nil || B.Percentile <= 0 || B.Percentile > 100 {
+				return nil, fmt.Errorf("invalid budget %q, the percentile must be between 0 and 100", part)
+			}
+		}
+		budgets = append(budgets, B)
+	}
+	return budgets, nil
+}
+
+// addSummary adds the totals of summary to total
+func addSummary(total *frisby.JsonSummary, summary frisby.JsonSummary) {
+	total.Frisbies += summary.Frisbies
+	total.Failed += summary.Failed
+	total.NumRequest += summary.NumRequest
+	total.NumAsserts += summary.NumAsserts
+	total.NumErrored += summary.NumErrored
+	total.ExecutionTime += summary.ExecutionTime
+	total.Budgets = append(total.Budgets, summary.Budgets...)
+}
+
+// importSpec writes the YAML spec of the -import-postman or -import-curl file
+func importSpec() {
+	var spec *frisby.Spec
+	var warnings []string
+	var err error
+	if *import_postman != "" {
+		spec, warnings, err = frisby.LoadPostman(*import_postman)
+	} else {
+		spec, err = loadCurl(*import_curl)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	os.Stdout.Write(data)
+}
+
+// loadCurl reads a spec from a file of curl commands separated by blank lines
+func loadCurl(filename string) (*frisby.Spec, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	spec := &frisby.Spec{Name: filename}
+	command := ""
+	lines := append(strings.Split(string(data), "\n"), "")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			command += line + "\n"
+			continue
+		}
+		if strings.TrimSpace(command) == "" {
+			continue
+		}
+		test, err := frisby.ParseCurl(command)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", filename, i, err)
+		}
+		spec.Tests = append(spec.Tests, *test)
+		command = ""
+	}
+	return spec, nil
+}
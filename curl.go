@@ -0,0 +1,236 @@
+package frisby
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mozillazg/request"
+)
+
+// AsCurl renders the coming or sent request as a curl command
+//
+// It includes the method, the URL with Params, Headers, Cookies, BasicAuth,
+// Proxy and the Json, Files, Form data or raw body. A SetBodyReader() body
+// is read from stdin.
+// Placeholders are only filled in after Send().
+func (F *Frisby) AsCurl() string {
+	method := F.method()
+
+	// options and their values, one pair per line
+	options := make([]string, 0)
+	for _, key := range F.headerKeys() {
+		options = append(options, "-H", key+": "+F.Req.Headers[key])
+	}
+	if cookies := F.cookieHeader(); cookies != "" {
+		options = append(options, "-b", cookies)
+	}
+	if auth := F.Req.BasicAuth; auth.Username != "" {
+		options = append(options, "-u", auth.Username+":"+auth.Password)
+	}
+	if F.Req.Proxy != "" {
+		options = append(options, "-x", F.Req.Proxy)
+	}
+
+	has_body := true
+	switch {
+	case F.body != nil:
+		options = append(options, "--data-raw", string(F.body))
+	case F.bodyReader != nil:
+		options = append(options, "--data-binary", "@-")
+	case F.Req.Json != nil:
+		data, err := json.Marshal(F.Req.Json)
+		if err != nil {
+			data = []byte(err.Error())
+		}
+		if _, ok := F.Req.Headers["Content-Type"]; !ok {
+			options = append(options, "-H", "Content-Type: application/json")
+		}
+		options = append(options, "--data-raw", string(data))
+	case len(F.Req.Files) > 0:
+		for _, file := range F.Req.Files {
+			options = append(options, "-F", fmt.Sprintf("%s=@%s;filename=%s", file.FieldName, curlFormQuote(filePath(file)), curlFormQuote(file.FileName)))
+		}
+		for _, key := range sortedKeys(F.Req.Data) {
+			options = append(options, "--form-string", key+"="+F.Req.Data[key])
+		}
+	case len(F.Req.Data) > 0:
+		for _, key := range sortedKeys(F.Req.Data) {
+			options = append(options, "--data-urlencode", key+"="+F.Req.Data[key])
+		}
+	default:
+		has_body = false
+	}
+
+	// curl picks GET, HEAD or POST from the other options
+	first := "curl"
+	switch {
+	case method == "HEAD":
+		first += " --head"
+	case method == "GET" && !has_body:
+	case method == "POST" && has_body:
+	default:
+		first += " -X " + method
+	}
+	lines := []string{first + " " + shellQuote(F.requestUrl())}
+	for i := 0; i < len(options); i += 2 {
+		lines = append(lines, options[i]+" "+shellQuote(options[i+1]))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// Prints the coming or sent request as a curl command
+func (F *Frisby) PrintCurl() *Frisby {
+	fmt.Println(F.AsCurl())
+	return F
+}
+
+// AsHttpFile renders the coming or sent request in the .http file format
+// of the VS Code REST Client and JetBrains HTTP Client
+//
+// Multipart Files refer to the local file with "< path". A SetBodyReader()
+// body is left out.
+func (F *Frisby) AsHttpFile() string {
+	lines := []string{fmt.Sprintf("### %s", F.Name), fmt.Sprintf("%s %s", F.method(), F.requestUrl())}
+
+	body := ""
+	content_type := ""
+	switch {
+	case F.body != nil:
+		body = string(F.body)
+	case F.Req.Json != nil:
+		data, err := json.MarshalIndent(F.Req.Json, "", "  ")
+		if err != nil {
+			data = []byte(err.Error())
+		}
+		content_type = "application/json"
+		body = string(data)
+	case len(F.Req.Files) > 0:
+		boundary := "FrisbyBoundary"
+		content_type = "multipart/form-data; boundary=" + boundary
+		parts := make([]string, 0)
+		for _, file := range F.Req.Files {
+			parts = append(parts,
+				"--"+boundary,
+				fmt.Sprintf("Content-Disposition: form-data; name=%q; filename=%q", file.FieldName, file.FileName),
+				"",
+				"< "+filePath(file))
+		}
+		for _, key := range sortedKeys(F.Req.Data) {
+			parts = append(parts,
+				"--"+boundary,
+				fmt.Sprintf("Content-Disposition: form-data; name=%q", key),
+				"",
+				F.Req.Data[key])
+		}
+		parts = append(parts, "--"+boundary+"--")
+		body = strings.Join(parts, "\n")
+	case len(F.Req.Data) > 0:
+		values := url.Values{}
+		for key, value := range F.Req.Data {
+			values.Set(key, value)
+		}
+		content_type = "application/x-www-form-urlencoded"
+		body = values.Encode()
+	}
+
+	if content_type != "" {
+		if _, ok := F.Req.Headers["Content-Type"]; !ok {
+			lines = append(lines, "Content-Type: "+content_type)
+		}
+	}
+	for _, key := range F.headerKeys() {
+		lines = append(lines, key+": "+F.Req.Headers[key])
+	}
+	if cookies := F.cookieHeader(); cookies != "" {
+		lines = append(lines, "Cookie: "+cookies)
+	}
+	if auth := F.Req.BasicAuth; auth.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		lines = append(lines, "Authorization: Basic "+credentials)
+	}
+	if body != "" {
+		lines = append(lines, "", body)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (F *Frisby) method() string {
+	if F.Method == "" {
+		return "GET"
+	}
+	return F.Method
+}
+
+// requestUrl returns the URL with the url Params added to its query
+func (F *Frisby) requestUrl() string {
+	if len(F.Req.Params) == 0 {
+		return F.Url
+	}
+	u, err := url.Parse(F.Url)
+	if err != nil {
+		return F.Url
+	}
+	query := u.Query()
+	for key, value := range F.Req.Params {
+		query.Add(key, value)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// headerKeys returns the sorted keys of the request Headers, leaving out
+// the unchanged request.DefaultHeaders, as clients send their own
+func (F *Frisby) headerKeys() []string {
+	keys := make([]string, 0, len(F.Req.Headers))
+	for _, key := range sortedKeys(F.Req.Headers) {
+		if value, ok := request.DefaultHeaders[key]; !ok || value != F.Req.Headers[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// filePath returns the path of a file added by AddFile(),
+// or the file name for other readers
+func filePath(file request.FileField) string {
+	if f, ok := file.File.(*os.File); ok {
+		return f.Name()
+	}
+	return file.FileName
+}
+
+func (F *Frisby) cookieHeader() string {
+	cookies := make([]string, 0, len(F.Req.Cookies))
+	for _, key := range sortedKeys(F.Req.Cookies) {
+		cookies = append(cookies, key+"="+F.Req.Cookies[key])
+	}
+	return strings.Join(cookies, "; ")
+}
+
+// shellQuote quotes str for POSIX shells
+func shellQuote(str string) string {
+	if str != "" && strings.IndexFunc(str, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@=,+%", r))
+	}) == -1 {
+		return str
+	}
+	return "'" + strings.Replace(str, "'", `'\''`, -1) + "'"
+}
+
+// curlFormQuote double quotes a -F file path or name containing ; or ,
+// which curl would otherwise take as the start of another modifier
+func curlFormQuote(str string) string {
+	if !strings.ContainsAny(str, ";,\"") {
+		return str
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str) + `"`
+}
+
+// indent prefixes every line of str
+func indent(str, prefix string) string {
+	return prefix + strings.Replace(str, "\n", "\n"+prefix, -1)
+}
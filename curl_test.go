@@ -0,0 +1,168 @@
+package frisby_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestAsCurl(t *testing.T) {
+	tests := []struct {
+		F        *frisby.Frisby
+		expected string
+	}{
+		{
+			frisby.Create("Test AsCurl get").Get("http://api.test/users"),
+			`curl http://api.test/users`,
+		},
+		{
+			frisby.Create("Test AsCurl head").Head("http://api.test/users").BasicAuth("user", "p@ss word"),
+			`curl --head http://api.test/users \
+  -u 'user:p@ss word'`,
+		},
+		{
+			frisby.Create("Test AsCurl form").
+				Post("http://api.test/login").
+				SetProxy("http://proxy:3128").
+				SetData("user", "alice").
+				SetData("next", "/home?tab=1"),
+			`curl http://api.test/login \
+  -x http://proxy:3128 \
+  --data-urlencode 'next=/home?tab=1' \
+  --data-urlencode user=alice`,
+		},
+		{
+			frisby.Create("Test AsCurl delete").Delete("http://api.test/users/1").SetCookie("b", "2").SetCookie("a", "1"),
+			`curl -X DELETE http://api.test/users/1 \
+  -b 'a=1; b=2'`,
+		},
+		{
+			frisby.Create("Test AsCurl params").
+				Get("http://api.test/users?tag=a").
+				SetParam("tag", "b").
+				SetHeader("User-Agent", "frisby-test"),
+			`curl 'http://api.test/users?tag=a&tag=b' \
+  -H 'User-Agent: frisby-test'`,
+		},
+	}
+	for _, test := range tests {
+		if curl := test.F.AsCurl(); curl != test.expected {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", test.F.Name, test.expected, curl)
+		}
+	}
+}
+
+func TestAsCurlFiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "avatar.png")
+	if err := ioutil.WriteFile(filename, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	F := frisby.Create("Test AsCurl files").
+		Post("http://api.test/upload").
+		AddFileByKey("avatar", filename).
+		SetData("title", "me")
+
+	expected := `curl http://api.test/upload \
+  -F 'avatar=@` + filename + `;filename=avatar.png' \
+  --form-string title=me`
+	if curl := F.AsCurl(); curl != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, curl)
+	}
+
+	http_file := F.AsHttpFile()
+	for _, line := range []string{
+		"### Test AsCurl files",
+		"POST http://api.test/upload",
+		"Content-Type: multipart/form-data; boundary=FrisbyBoundary",
+		`Content-Disposition: form-data; name="avatar"; filename="avatar.png"`,
+		"< " + filename,
+		"--FrisbyBoundary--",
+	} {
+		if !strings.Contains(http_file, line+"\n") {
+			t.Errorf("Expected the HTTP file to contain %q, but got\n%s", line, http_file)
+		}
+	}
+}
+
+func TestAsCurlFileQuoting(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), `a;b,"c".png`)
+	if err := ioutil.WriteFile(filename, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	F := frisby.Create("Test AsCurl file quoting").
+		Post("http://api.test/upload").
+		AddFileByKey("photo", filename)
+
+	quoted := `"` + strings.Replace(filename, `"`, `\"`, -1) + `"`
+	expected := "curl http://api.test/upload \\\n" +
+		"  -F 'photo=@" + quoted + `;filename="a;b,\"c\".png"'`
+	curl := F.AsCurl()
+	if curl != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, curl)
+	}
+
+	test, err := frisby.ParseCurl(curl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.Files["photo"] != filename {
+		t.Errorf("Expected the file %q, but got %v", filename, test.Files)
+	}
+}
+
+func TestAsHttpFile(t *testing.T) {
+	F := frisby.Create("Test AsHttpFile").
+		Patch("http://api.test/users/1").
+		SetParam("v", "2").
+		SetHeader("X-Trace", "abc").
+		BasicAuth("user", "pass").
+		SetJson(map[string]int{"age": 42})
+
+	expected := `### Test AsHttpFile
+PATCH http://api.test/users/1?v=2
+Content-Type: application/json
+X-Trace: abc
+Authorization: Basic dXNlcjpwYXNz
+
+{
+  "age": 42
+}
+`
+	if http_file := F.AsHttpFile(); http_file != expected {
+		t.Errorf("Expected\n%s\nbut got\n%s", expected, http_file)
+	}
+}
+
+func TestPrintCurlOnFail(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	suite := frisby.NewSuite("Test PrintCurlOnFail")
+	suite.PrintCurlOnFail = true
+	suite.PrintProgressDot = false
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	suite.Create("Test PrintCurlOnFail").
+		Get(ts.URL).
+		Send().
+		ExpectStatus(404).
+		PrintReport()
+	w.Close()
+	os.Stdout = stdout
+
+	out, _ := ioutil.ReadAll(r)
+	if !strings.Contains(string(out), "        curl "+ts.URL) {
+		t.Errorf("Expected the curl command in the report, but got\n%s", out)
+	}
+}
@@ -0,0 +1,449 @@
+package frisby
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// curl options which take a value and are read by ParseCurl()
+var curlValueOptions = map[string]string{
+	"-X": "request", "--request": "request",
+	"-H": "header", "--header": "header",
+	"-d": "data", "--data": "data", "--data-ascii": "data", "--data-binary": "data",
+	"--data-raw":       "data-raw",
+	"--data-urlencode": "data-urlencode",
+	"--json":           "json",
+	"-F":               "form", "--form": "form",
+	"--form-string": "form-string",
+	"-u":            "user", "--user": "user",
+	"-x": "proxy", "--proxy": "proxy",
+	"-b": "cookie", "--cookie": "cookie",
+	"-A": "user-agent", "--user-agent": "user-agent",
+	"-e": "referer", "--referer": "referer",
+	"--url": "url",
+}
+
+// curl options without a value which are read by ParseCurl()
+var curlFlagOptions = map[string]string{
+	"-G": "get", "--get": "get",
+	"-I": "head", "--head": "head",
+}
+
+// curl options which do not change the request, with whether they take a value
+var curlIgnoredOptions = map[string]bool{
+	"-s": false, "--silent": false,
+	"-S": false, "--show-error": false,
+	"-v": false, "--verbose": false,
+	"-L": false, "--location": false,
+	"-k": false, "--insecure": false,
+	"-i": false, "--include": false,
+	"-f": false, "--fail": false,
+	"-g": false, "--globoff": false,
+	"-N": false, "--no-buffer": false,
+	"-#": false, "--progress-bar": false,
+	"--compressed": false,
+	"-o":           true, "--output": true,
+	"-m": true, "--max-time": true,
+	"-w": true, "--write-out": true,
+	"-c": true, "--cookie-jar": true,
+	"--connect-timeout": true,
+	"--retry":           true,
+}
+
+// ParseCurl parses a curl command line into a SpecTest
+//
+// It reads the method, URL, headers, -d data, --json, -F files and form
+// fields, -u auth, -x proxy, -b and Cookie header cookies, -G and -I.
+// Data which is a JSON object or array becomes the Json body, form data
+// the Data and any other data the raw Body. Output options like -s or -o are ignored,
+// and unknown options are an error. The test is named "METHOD url".
+func ParseCurl(command string) (*SpecTest, error) {
+	words, err := shellSplit(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return nil, fmt.Errorf("not a curl command: %q", command)
+	}
+
+	T := new(SpecTest)
+	datas := make([]string, 0)
+	forms := make([]string, 0)
+	is_get, is_head, is_json := false, false, false
+
+	set := func(m *map[string]string, key, value string) {
+		if *m == nil {
+			*m = make(map[string]string)
+		}
+		(*m)[key] = value
+	}
+
+	apply := func(option, name, value string) error {
+		switch name {
+		case "request":
+			T.Method = standardMethod(value)
+		case "header":
+			idx := strings.Index(value, ":")
+			if idx < 0 {
+				return fmt.Errorf("%s %q is not a header", option, value)
+			}
+			key, val := strings.TrimSpace(value[:idx]), strings.TrimSpace(value[idx+1:])
+			if strings.EqualFold(key, "Cookie") {
+				return parseCookies(val, func(k, v string) { set(&T.Cookies, k, v) })
+			}
+			set(&T.Headers, key, val)
+		case "data", "json":
+			if strings.HasPrefix(value, "@") {
+				content, err := ioutil.ReadFile(value[1:])
+				if err != nil {
+					return err
+				}
+				value = string(content)
+				if option != "--data-binary" {
+					value = strings.Replace(strings.Replace(value, "\r", "", -1), "\n", "", -1)
+				}
+			}
+			is_json = is_json || name == "json"
+			datas = append(datas, value)
+		case "data-raw":
+			datas = append(datas, value)
+		case "data-urlencode":
+			idx := strings.IndexAny(value, "=@")
+			if idx <= 0 {
+				return fmt.Errorf("%s %q has no name", option, value)
+			}
+			key, val := value[:idx], value[idx+1:]
+			if value[idx] == '@' {
+				content, err := ioutil.ReadFile(val)
+				if err != nil {
+					return err
+				}
+				val = string(content)
+			}
+			datas = append(datas, url.QueryEscape(key)+"="+url.QueryEscape(val))
+		case "form":
+			forms = append(forms, value)
+		case "form-string":
+			idx := strings.Index(value, "=")
+			if idx <= 0 {
+				return fmt.Errorf("%s %q has no name", option, value)
+			}
+			set(&T.Data, value[:idx], value[idx+1:])
+		case "user":
+			T.BasicAuth = &SpecBasicAuth{User: value}
+			if idx := strings.Index(value, ":"); idx >= 0 {
+				T.BasicAuth = &SpecBasicAuth{User: value[:idx], Password: value[idx+1:]}
+			}
+		case "proxy":
+			T.Proxy = value
+		case "cookie":
+			if !strings.Contains(value, "=") {
+				return fmt.Errorf("%s %q reads a cookie file, which is not supported", option, value)
+			}
+			return parseCookies(value, func(k, v string) { set(&T.Cookies, k, v) })
+		case "user-agent":
+			set(&T.Headers, "User-Agent", value)
+		case "referer":
+			set(&T.Headers, "Referer", value)
+		case "url":
+			if T.Url != "" {
+				return fmt.Errorf("more than one URL: %q and %q", T.Url, value)
+			}
+			T.Url = value
+		case "get":
+			is_get = true
+		case "head":
+			is_head = true
+		}
+		return nil
+	}
+
+	args := words[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		next := func(option string) (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s is missing a value", option)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch {
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			if err := apply("url", "url", arg); err != nil {
+				return nil, err
+			}
+
+		case strings.HasPrefix(arg, "--"):
+			option, value, has_value := arg, "", false
+			if idx := strings.Index(arg, "="); idx >= 0 {
+				option, value, has_value = arg[:idx], arg[idx+1:], true
+			}
+			if name, ok := curlValueOptions[option]; ok {
+				if !has_value {
+					if value, err = next(option); err != nil {
+						return nil, err
+					}
+				}
+				if err := apply(option, name, value); err != nil {
+					return nil, err
+				}
+			} else if name, ok := curlFlagOptions[option]; ok {
+				apply(option, name, "")
+			} else if takes_value, ok := curlIgnoredOptions[option]; ok {
+				if takes_value && !has_value {
+					if _, err := next(option); err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				return nil, fmt.Errorf("unsupported curl option %s", option)
+			}
+
+		default:
+			// short options may be combined, as in -sSL or -XPOST
+			for j := 1; j < len(arg); j++ {
+				option := "-" + arg[j:j+1]
+				if name, ok := curlValueOptions[option]; ok {
+					value := arg[j+1:]
+					if value == "" {
+						if value, err = next(option); err != nil {
+							return nil, err
+						}
+					}
+					if err := apply(option, name, value); err != nil {
+						return nil, err
+					}
+					break
+				} else if name, ok := curlFlagOptions[option]; ok {
+					apply(option, name, "")
+				} else if takes_value, ok := curlIgnoredOptions[option]; ok {
+					if takes_value {
+						if j+1 == len(arg) {
+							if _, err := next(option); err != nil {
+								return nil, err
+							}
+						}
+						break
+					}
+				} else {
+					return nil, fmt.Errorf("unsupported curl option %s", option)
+				}
+			}
+		}
+	}
+
+	if T.Url == "" {
+		return nil, fmt.Errorf("curl command has no URL")
+	}
+
+	for _, form := range forms {
+		idx := strings.Index(form, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("-F %q has no name", form)
+		}
+		key, value := form[:idx], form[idx+1:]
+		switch {
+		case strings.HasPrefix(value, "@"):
+			// drop ;type= and ;filename= modifiers
+			set(&T.Files, key, curlFormPath(value[1:]))
+		case strings.HasPrefix(value, "<"):
+			content, err := ioutil.ReadFile(curlFormPath(value[1:]))
+			if err != nil {
+				return nil, err
+			}
+			set(&T.Data, key, string(content))
+		default:
+			set(&T.Data, key, value)
+		}
+	}
+
+	if len(datas) > 0 {
+		data := strings.Join(datas, "&")
+		switch {
+		case is_get:
+			values, err := url.ParseQuery(data)
+			if err != nil {
+				return nil, err
+			}
+			for key := range values {
+				set(&T.Params, key, values.Get(key))
+			}
+		case is_json || len(datas) == 1 && isJsonDocument(data):
+			dec := json.NewDecoder(strings.NewReader(data))
+			dec.UseNumber()
+			if err := dec.Decode(&T.Json); err != nil {
+				return nil, fmt.Errorf("curl --json data: %v", err)
+			}
+		case !isFormData(data):
+			T.Body = data
+		default:
+			for _, pair := range strings.Split(data, "&") {
+				idx := strings.Index(pair, "=")
+				key, err := url.QueryUnescape(pair[:idx])
+				if err != nil {
+					return nil, err
+				}
+				value, err := url.QueryUnescape(pair[idx+1:])
+				if err != nil {
+					return nil, err
+				}
+				set(&T.Data, key, value)
+			}
+		}
+	}
+
+	if T.Method == "" {
+		switch {
+		case is_head:
+			T.Method = "HEAD"
+		case !is_get && (len(datas) > 0 || len(forms) > 0):
+			T.Method = "POST"
+		default:
+			T.Method = "GET"
+		}
+	}
+	T.Name = T.Method + " " + T.Url
+	return T, nil
+}
+
+// Creates a new Frisby object in frisby.Global from a curl command line
+//
+// See ParseCurl() for the options read.
+func FromCurl(command string) (*Frisby, error) {
+	return Global.FromCurl(command)
+}
+
+// Creates a new Frisby object in the Suite from a curl command line
+//
+// See ParseCurl() for the options read.
+func (G *Suite) FromCurl(command string) (*Frisby, error) {
+	T, err := ParseCurl(command)
+	if err != nil {
+		return nil, err
+	}
+	return T.create(G), nil
+}
+
+// parseCookies calls set for each name=value pair of a Cookie header
+func parseCookies(header string, set func(name, value string)) error {
+	for _, pair := range strings.Split(header, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		idx := strings.Index(pair, "=")
+		if idx <= 0 {
+			return fmt.Errorf("cookie %q has no name", pair)
+		}
+		set(pair[:idx], pair[idx+1:])
+	}
+	return nil
+}
+
+// isFormData reports whether data is made of url encoded key=value pairs
+func isFormData(data string) bool {
+	for _, pair := range strings.Split(data, "&") {
+		idx := strings.Index(pair, "=")
+		if idx <= 0 {
+			return false
+		}
+		if _, err := url.ParseQuery(pair); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isJsonDocument reports whether data is a JSON object or array
+func isJsonDocument(data string) bool {
+	trimmed := strings.TrimSpace(data)
+	return (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed))
+}
+
+// shellSplit splits a command line into words like a POSIX shell,
+// handling quotes, backslash escapes and line continuations
+func shellSplit(command string) ([]string, error) {
+	words := make([]string, 0)
+	var word bytes.Buffer
+	in_word := false
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(command) {
+				return nil, fmt.Errorf("command ends with a backslash")
+			}
+			i++
+			if command[i] == '\n' {
+				continue
+			}
+			word.WriteByte(command[i])
+			in_word = true
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			in_word = true
+		case c == '"':
+			i++
+			for ; i < len(command) && command[i] != '"'; i++ {
+				if command[i] == '\\' && i+1 < len(command) && strings.IndexByte("$`\"\\\n", command[i+1]) >= 0 {
+					i++
+					if command[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(command[i])
+			}
+			if i == len(command) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			in_word = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if in_word {
+				words = append(words, word.String())
+				word.Reset()
+				in_word = false
+			}
+		default:
+			word.WriteByte(c)
+			in_word = true
+		}
+	}
+	if in_word {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// curlFormPath returns the file path of a -F value without its modifiers,
+// unquoting a path in double quotes
+func curlFormPath(value string) string {
+	if !strings.HasPrefix(value, `"`) {
+		return strings.SplitN(value, ";", 2)[0]
+	}
+	path := make([]byte, 0, len(value))
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				path = append(path, value[i])
+			}
+		case '"':
+			return string(path)
+		default:
+			path = append(path, value[i])
+		}
+	}
+	return string(path)
+}
@@ -0,0 +1,148 @@
+package frisby_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestParseCurl(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected frisby.SpecTest
+	}{
+		{
+			`curl http://api.test/users`,
+			frisby.SpecTest{Name: "GET http://api.test/users", Method: "GET", Url: "http://api.test/users"},
+		},
+		{
+			`curl -sSL -XDELETE 'http://api.test/users/1' -H 'Accept: application/json' -b 'a=1; b=2'`,
+			frisby.SpecTest{
+				Name: "DELETE http://api.test/users/1", Method: "DELETE", Url: "http://api.test/users/1",
+				SpecRequest: frisby.SpecRequest{
+					Headers: map[string]string{"Accept": "application/json"},
+					Cookies: map[string]string{"a": "1", "b": "2"},
+				},
+			},
+		},
+		{
+			"curl http://api.test/login \\\n  -u \"user:p@ss word\" \\\n  -x http://proxy:3128 \\\n  --data-urlencode 'next=/home?tab=1' -d user=alice",
+			frisby.SpecTest{
+				Name: "POST http://api.test/login", Method: "POST", Url: "http://api.test/login",
+				SpecRequest: frisby.SpecRequest{
+					BasicAuth: &frisby.SpecBasicAuth{User: "user", Password: "p@ss word"},
+					Proxy:     "http://proxy:3128",
+					Data:      map[string]string{"next": "/home?tab=1", "user": "alice"},
+				},
+			},
+		},
+		{
+			`curl --request PUT --url http://api.test/users/1 --header "Cookie: session=abc" --data-raw '{"name": "it'\''s", "age": 3}'`,
+			frisby.SpecTest{
+				Name: "PUT http://api.test/users/1", Method: "PUT", Url: "http://api.test/users/1",
+				SpecRequest: frisby.SpecRequest{
+					Cookies: map[string]string{"session": "abc"},
+					Json:    map[string]interface{}{"name": "it's", "age": json.Number("3")},
+				},
+			},
+		},
+		{
+			`curl -F avatar=@/tmp/me.png\;type=image/png -F name=alice --form-string 'note=@home' http://api.test/upload`,
+			frisby.SpecTest{
+				Name: "POST http://api.test/upload", Method: "POST", Url: "http://api.test/upload",
+				SpecRequest: frisby.SpecRequest{
+					Files: map[string]string{"avatar": "/tmp/me.png"},
+					Data:  map[string]string{"name": "alice", "note": "@home"},
+				},
+			},
+		},
+		{
+			`curl -G -d q=frisby -d page=2 -A frisby/1.0 http://api.test/search`,
+			frisby.SpecTest{
+				Name: "GET http://api.test/search", Method: "GET", Url: "http://api.test/search",
+				SpecRequest: frisby.SpecRequest{
+					Headers: map[string]string{"User-Agent": "frisby/1.0"},
+					Params:  map[string]string{"q": "frisby", "page": "2"},
+				},
+			},
+		},
+		{
+			`curl -X PROPFIND -H 'Content-Type: application/xml' -d '<propfind><prop/></propfind>' http://api.test/dav`,
+			frisby.SpecTest{
+				Name: "PROPFIND http://api.test/dav", Method: "PROPFIND", Url: "http://api.test/dav",
+				Body: "<propfind><prop/></propfind>",
+				SpecRequest: frisby.SpecRequest{
+					Headers: map[string]string{"Content-Type": "application/xml"},
+				},
+			},
+		},
+		{
+			`curl -I -o /dev/null --max-time=5 http://api.test/health`,
+			frisby.SpecTest{Name: "HEAD http://api.test/health", Method: "HEAD", Url: "http://api.test/health"},
+		},
+		{
+			`curl -X delete http://api.test/users/1`,
+			frisby.SpecTest{Name: "DELETE http://api.test/users/1", Method: "DELETE", Url: "http://api.test/users/1"},
+		},
+		{
+			`curl -X purge http://api.test/cache`,
+			frisby.SpecTest{Name: "purge http://api.test/cache", Method: "purge", Url: "http://api.test/cache"},
+		},
+	}
+
+	for _, test := range tests {
+		T, err := frisby.ParseCurl(test.command)
+		if err != nil {
+			t.Errorf("ParseCurl(%q) failed: %v", test.command, err)
+			continue
+		}
+		if !reflect.DeepEqual(*T, test.expected) {
+			t.Errorf("ParseCurl(%q)\n  got:      %+v\n  expected: %+v", test.command, *T, test.expected)
+		}
+	}
+}
+
+func TestParseCurlErrors(t *testing.T) {
+	for _, command := range []string{
+		`wget http://api.test`,
+		`curl -H`,
+		`curl --unknown http://api.test`,
+		`curl 'http://api.test`,
+		`curl -b cookies.txt http://api.test`,
+		`curl http://api.test/a http://api.test/b`,
+		`curl -s`,
+	} {
+		if _, err := frisby.ParseCurl(command); err == nil {
+			t.Errorf("Expected ParseCurl(%q) to fail", command)
+		}
+	}
+}
+
+func TestFromCurl(t *testing.T) {
+	ts := newEchoServer()
+	defer ts.Close()
+
+	F, err := frisby.FromCurl(`curl ` + ts.URL + `/items?q=frisby -u user:pass -b session=abc -d '{"id": 1}'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	F.T = t
+	F.Send().
+		ExpectStatus(200).
+		ExpectJson("path", "/items").
+		ExpectJson("query", "frisby").
+		ExpectJson("auth", "Basic dXNlcjpwYXNz").
+		ExpectJson("session", "session=abc").
+		ExpectJson("body", `{"id":1}`)
+
+	// AsCurl renders what FromCurl reads
+	G, err := frisby.FromCurl(F.AsCurl())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if G.Method != F.Method || G.Url != F.Url || !reflect.DeepEqual(G.Req.Cookies, F.Req.Cookies) {
+		t.Errorf("Expected %q to parse back into the same request, but got %s %s", F.AsCurl(), G.Method, G.Url)
+	}
+}
@@ -0,0 +1,326 @@
+package frisby
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DigestCredentials are the user and password of HTTP Digest authentication
+//
+// They keep the last challenge of each host, so the following requests
+// answer it right away, with an incremented nonce count.
+type DigestCredentials struct {
+	Username string
+	Password string
+
+	mu     sync.Mutex
+	nonces map[string]*digestNonce
+}
+
+// digestNonce is the last challenge of a host and the count of its nonce uses
+type digestNonce struct {
+	challenge *digestChallenge
+	count     uint32
+}
+
+// next returns the last challenge of host and the next count of its nonce,
+// or nil when there was none
+func (C *DigestCredentials) next(host string) (*digestChallenge, uint32) {
+	C.mu.Lock()
+	defer C.mu.Unlock()
+	nonce, ok := C.nonces[host]
+	if !ok {
+		return nil, 0
+	}
+	nonce.count++
+	return nonce.challenge, nonce.count
+}
+
+// store keeps challenge as the last one of host, and returns its first count
+func (C *DigestCredentials) store(host string, challenge *digestChallenge) uint32 {
+	C.mu.Lock()
+	defer C.mu.Unlock()
+	if C.nonces == nil {
+		C.nonces = make(map[string]*digestNonce)
+	}
+	C.nonces[host] = &digestNonce{challenge: challenge, count: 1}
+	return 1
+}
+
+// Set Digest authentication values for the coming request
+//
+// Send() answers the Digest challenge of a 401 response, as described
+// by RFC 7616, with the MD5 or SHA-256 algorithms and the auth or auth-int
+// qop. The time of the challenge is Timing.Auth, and both requests are in
+// Frisby.Requests. Later requests with the same credentials, like those of
+// a Suite, use the nonce again and are only challenged when it is stale.
+func (F *Frisby) DigestAuth(user, passwd string) *Frisby {
+	F.Digest = &DigestCredentials{Username: user, Password: passwd}
+	return F
+}
+
+// Set Digest authentication values for the coming requests
+func (G *Suite) DigestAuth(user, passwd string) *Suite {
+	G.Digest = &DigestCredentials{Username: user, Password: passwd}
+	return G
+}
+
+// digestHashes are the supported algorithms, strongest first
+var digestHashes = []struct {
+	name string
+	hash func() hash.Hash
+}{
+	{"SHA-256", sha256.New},
+	{"MD5", md5.New},
+}
+
+// digestChallenge is a parsed WWW-Authenticate Digest header
+type digestChallenge struct {
+	params    map[string]string
+	algorithm string
+	hash      func() hash.Hash
+	session   bool
+}
+
+// parseDigestChallenges returns the supported Digest challenge of resp
+// with the strongest algorithm
+func parseDigestChallenges(resp *http.Response) (*digestChallenge, error) {
+	var best *digestChallenge
+	rank := len(digestHashes)
+	found := false
+	for _, header := range resp.Header.Values("WWW-Authenticate") {
+		if len(header) < 7 || !strings.EqualFold(header[:7], "Digest ") {
+			continue
+		}
+		found = true
+		params := parseAuthParams(header[7:])
+		algorithm := params["algorithm"]
+		if algorithm == "" {
+			algorithm = "MD5"
+		}
+		session := strings.HasSuffix(strings.ToUpper(algorithm), "-SESS")
+		base := strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS")
+		for i, h := range digestHashes {
+			if h.name == base && i < rank {
+				rank = i
+				best = &digestChallenge{params: params, algorithm: algorithm, hash: h.hash, session: session}
+			}
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	if best == nil {
+		return nil, fmt.Errorf("digest auth: no supported algorithm in %q", resp.Header.Values("WWW-Authenticate"))
+	}
+	return best, nil
+}
+
+// parseAuthParams parses the comma separated key=value and key="value"
+// parameters of an authentication header
+func parseAuthParams(str string) map[string]string {
+	params := make(map[string]string)
+	for str = strings.TrimSpace(str); str != ""; {
+		eq := strings.Index(str, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(str[:eq]))
+		str = strings.TrimSpace(str[eq+1:])
+
+		var value string
+		if strings.HasPrefix(str, `"`) {
+			var buf strings.Builder
+			i := 1
+			for ; i < len(str) && str[i] != '"'; i++ {
+				if str[i] == '\\' && i+1 < len(str) {
+					i++
+				}
+				buf.WriteByte(str[i])
+			}
+			value = buf.String()
+			if i < len(str) {
+				i++
+			}
+			str = str[i:]
+		} else {
+			end := strings.Index(str, ",")
+			if end < 0 {
+				end = len(str)
+			}
+			value = strings.TrimSpace(str[:end])
+			str = str[end:]
+		}
+		params[key] = value
+		str = strings.TrimLeft(str, ", \t")
+	}
+	return params
+}
+
+// authorization returns the Authorization header answering the challenge
+// for req with the given body, using its nonce for the count-th time
+func (C *digestChallenge) authorization(creds *DigestCredentials, req *http.Request, body []byte, count uint32) (string, error) {
+	h := func(str string) string {
+		return hashHex(C.hash, []byte(str))
+	}
+	realm, nonce := C.params["realm"], C.params["nonce"]
+	uri := req.URL.RequestURI()
+
+	qop := ""
+	for _, option := range strings.Split(C.params["qop"], ",") {
+		option = strings.TrimSpace(option)
+		if option == "auth" || option == "auth-int" && qop == "" {
+			qop = option
+		}
+	}
+	if C.params["qop"] != "" && qop == "" {
+		return "", fmt.Errorf("digest auth: unsupported qop %q", C.params["qop"])
+	}
+
+	cnonce_bytes := make([]byte, 16)
+	if _, err := rand.Read(cnonce_bytes); err != nil {
+		return "", err
+	}
+	cnonce := hex.EncodeToString(cnonce_bytes)
+	nc := fmt.Sprintf("%08x", count)
+
+	ha1 := h(creds.Username + ":" + realm + ":" + creds.Password)
+	if C.session {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonce)
+	}
+	ha2 := h(req.Method + ":" + uri)
+	if qop == "auth-int" {
+		ha2 = h(req.Method + ":" + uri + ":" + hashHex(C.hash, body))
+	}
+	response := h(ha1 + ":" + nonce + ":" + ha2)
+	if qop != "" {
+		response = h(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	}
+
+	quote := func(str string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str) + `"`
+	}
+	fields := []string{
+		"username=" + quote(creds.Username),
+		"realm=" + quote(realm),
+		"nonce=" + quote(nonce),
+		"uri=" + quote(uri),
+		"algorithm=" + C.algorithm,
+		"response=" + quote(response),
+	}
+	if opaque, ok := C.params["opaque"]; ok {
+		fields = append(fields, "opaque="+quote(opaque))
+	}
+	if qop != "" {
+		fields = append(fields, "qop="+qop, "nc="+nc, "cnonce="+quote(cnonce))
+	}
+	return "Digest " + strings.Join(fields, ", "), nil
+}
+
+// digestTransport answers the Digest challenge of a 401 response
+// by sending the request again with an Authorization header
+type digestTransport struct {
+	creds *DigestCredentials
+	next  http.RoundTripper
+}
+
+func (D *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := D.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	host := req.URL.Host
+
+	// the nonce of the last challenge saves a round trip, unless it is stale
+	first := req
+	if challenge, count := D.creds.next(host); challenge != nil {
+		authorized, err := D.authorize(req, challenge, count)
+		if err != nil {
+			return nil, err
+		}
+		if authorized != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			first = authorized
+		}
+	}
+
+	resp, err := next.RoundTrip(first)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	fail := func(err error) (*http.Response, error) {
+		resp.Body.Close()
+		return nil, err
+	}
+	challenge, err := parseDigestChallenges(resp)
+	if err != nil {
+		return fail(err)
+	}
+	if challenge == nil {
+		// not a Digest challenge
+		return resp, nil
+	}
+
+	retry, err := D.authorize(req, challenge, D.creds.store(host, challenge))
+	if err != nil {
+		return fail(err)
+	}
+	if retry == nil {
+		// the body can not be sent again
+		return resp, nil
+	}
+
+	// read the challenge to the end, so its time is complete
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	return next.RoundTrip(retry)
+}
+
+// authorize returns a copy of req answering challenge with the count-th use
+// of its nonce, or nil when the body of req can not be sent again
+func (D *digestTransport) authorize(req *http.Request, challenge *digestChallenge, count uint32) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, nil
+		}
+		reader, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		body, err = ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	auth, err := challenge.authorization(D.creds, retry, body, count)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", auth)
+	return retry, nil
+}
+
+// digest installs the Digest credentials on the client of the Frisby
+// object and returns a function restoring the client
+func (F *Frisby) digest() func() {
+	creds := F.Digest
+	return F.wrapTransport(func(next http.RoundTripper) http.RoundTripper {
+		return &digestTransport{creds: creds, next: next}
+	})
+}
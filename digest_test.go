@@ -0,0 +1,196 @@
+package frisby_test
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+func digestHash(algorithm, str string) string {
+	var h hash.Hash = md5.New()
+	if algorithm == "SHA-256" {
+		h = sha256.New()
+	}
+	h.Write([]byte(str))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// digestResponse computes the RFC 7616 response, with the auth qop
+// when body is nil and auth-int otherwise
+func digestResponse(algorithm, user, realm, password, method, uri, nonce, nc, cnonce string, body []byte) string {
+	ha1 := digestHash(algorithm, user+":"+realm+":"+password)
+	qop, ha2 := "auth", digestHash(algorithm, method+":"+uri)
+	if body != nil {
+		qop, ha2 = "auth-int", digestHash(algorithm, method+":"+uri+":"+digestHash(algorithm, string(body)))
+	}
+	return digestHash(algorithm, ha1+":"+nonce+":"+nc+":"+cnonce+":"+qop+":"+ha2)
+}
+
+func TestDigestResponse(t *testing.T) {
+	// the examples of RFC 7616 section 3.9.1
+	for algorithm, expected := range map[string]string{
+		"MD5":     "8ca523f5e9506fed4657c9700eebdbec",
+		"SHA-256": "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1",
+	} {
+		response := digestResponse(algorithm, "Mufasa", "http-auth@example.org", "Circle of Life", "GET", "/dir/index.html",
+			"7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", "00000001", "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ", nil)
+		if response != expected {
+			t.Errorf("Expected the %s response %s, but got %s", algorithm, expected, response)
+		}
+	}
+}
+
+var digestParam = regexp.MustCompile(`(\w+)=(?:"((?:[^"\\]|\\.)*)"|([^,\s]+))`)
+
+// newDigestServer challenges requests with the given algorithms and qop,
+// and accepts the user "Mufasa" with the password "Circle of Life"
+// for the first two uses of its nonce
+func newDigestServer(qop string, algorithms ...string) *httptest.Server {
+	realm, nonce, opaque := "frisby@example.org", "dcd98b7102dd2f0e8b11d0f600bfb0c093", "5ccc069c403ebaf9f0171e9517f40e41"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		auth := r.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Digest ") {
+			params := make(map[string]string)
+			for _, match := range digestParam.FindAllStringSubmatch(auth, -1) {
+				params[match[1]] = match[2] + match[3]
+			}
+			var int_body []byte
+			if params["qop"] == "auth-int" {
+				int_body = body
+			}
+			expected := digestResponse(params["algorithm"], "Mufasa", realm, "Circle of Life", r.Method, r.URL.RequestURI(),
+				nonce, params["nc"], params["cnonce"], int_body)
+			if params["response"] == expected && params["opaque"] == opaque && params["uri"] == r.URL.RequestURI() &&
+				params["nc"] <= "00000002" {
+				fmt.Fprintf(w, `{"algorithm": %q, "qop": %q, "nc": %q, "body": %q}`, params["algorithm"], params["qop"], params["nc"], body)
+				return
+			}
+		}
+		for _, algorithm := range algorithms {
+			w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", qop="%s", algorithm=%s, nonce="%s", opaque="%s"`,
+				realm, qop, algorithm, nonce, opaque))
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(401)
+	}))
+}
+
+func TestDigestAuth(t *testing.T) {
+	ts := newDigestServer("auth", "MD5", "SHA-256")
+	defer ts.Close()
+
+	F := frisby.CreateT(t, "Test DigestAuth").
+		DigestAuth("Mufasa", "Circle of Life").
+		Get(ts.URL+"/dir/index.html?x=1").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("algorithm", "SHA-256").
+		ExpectJson("qop", "auth")
+
+	if F.Timing.Auth < 20*time.Millisecond || F.Timing.Total >= 20*time.Millisecond {
+		t.Errorf("Expected the challenge time in Timing.Auth, but got %+v", F.Timing)
+	}
+
+	frisby.CreateT(t, "Test DigestAuth wrong password").
+		DigestAuth("Mufasa", "Hakuna Matata").
+		Get(ts.URL).
+		Send().
+		ExpectStatus(401)
+}
+
+func TestDigestAuthMD5(t *testing.T) {
+	ts := newDigestServer("auth-int,auth", "MD5")
+	defer ts.Close()
+
+	G := frisby.NewSuite("Test DigestAuth MD5")
+	G.PrintProgressDot = false
+	G.DigestAuth("Mufasa", "Circle of Life")
+
+	G.CreateT(t, "Test DigestAuth MD5").
+		Post(ts.URL+"/items").
+		SetJson(map[string]int{"id": 1}).
+		Send().
+		ExpectStatus(200).
+		ExpectJson("algorithm", "MD5").
+		ExpectJson("qop", "auth").
+		ExpectJson("body", `{"id":1}`)
+}
+
+func TestDigestAuthInt(t *testing.T) {
+	ts := newDigestServer("auth-int", "SHA-256")
+	defer ts.Close()
+
+	frisby.CreateT(t, "Test DigestAuth auth-int").
+		DigestAuth("Mufasa", "Circle of Life").
+		Put(ts.URL+"/items/1").
+		SetJson(map[string]int{"id": 1}).
+		Send().
+		ExpectStatus(200).
+		ExpectJson("qop", "auth-int").
+		ExpectJson("body", `{"id":1}`)
+}
+
+func TestDigestAuthNonce(t *testing.T) {
+	ts := newDigestServer("auth", "SHA-256")
+	defer ts.Close()
+
+	har := frisby.NewHar("")
+	G := frisby.NewSuite("Test DigestAuth nonce")
+	G.PrintProgressDot = false
+	G.DigestAuth("Mufasa", "Circle of Life").SetHar(har)
+
+	F := G.CreateT(t, "Test DigestAuth challenge").
+		Get(ts.URL+"/a").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("nc", "00000001")
+	if len(F.Requests) != 2 || F.Requests[0].Status != 401 || F.Requests[1].Status != 200 {
+		t.Errorf("Expected the challenge and its answer, but got %+v", F.Requests)
+	}
+	if report := F.Report(); len(report.Requests) != 2 {
+		t.Errorf("Expected the challenge in the report, but got %+v", report.Requests)
+	}
+
+	F = G.CreateT(t, "Test DigestAuth nonce again").
+		Get(ts.URL+"/b").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("nc", "00000002")
+	if len(F.Requests) != 1 || F.Timing.Auth != 0 {
+		t.Errorf("Expected a single request without challenge, but got %+v and %+v", F.Requests, F.Timing)
+	}
+	if report := F.Report(); report.Requests != nil {
+		t.Errorf("Expected no requests in the report, but got %+v", report.Requests)
+	}
+
+	// the server only accepts two uses of a nonce
+	F = G.CreateT(t, "Test DigestAuth stale nonce").
+		Get(ts.URL+"/c").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("nc", "00000001")
+	if len(F.Requests) != 2 {
+		t.Errorf("Expected the stale nonce to be challenged, but got %+v", F.Requests)
+	}
+
+	entries := har.Entries()
+	statuses := make([]int, 0, len(entries))
+	for _, E := range entries {
+		statuses = append(statuses, E.Response.Status)
+	}
+	if fmt.Sprint(statuses) != "[401 200 200 401 200]" {
+		t.Errorf("Expected a HAR entry for every request, but got %v", statuses)
+	}
+}
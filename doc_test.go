@@ -84,3 +84,19 @@ func ExampleFrisby_AfterJson() {
 
 	// Output: url = http://httpbin.org/post
 }
+
+func ExampleFrisby_AsCurl() {
+	frisby.Create("Test AsCurl").
+		Put("http://api.test/users/1").
+		SetParam("notify", "true").
+		SetHeader("Accept", "application/json").
+		SetCookie("session", "abc").
+		SetJson(map[string]string{"name": "O'Brien"}).
+		PrintCurl()
+
+	// Output: curl -X PUT 'http://api.test/users/1?notify=true' \
+	//   -H 'Accept: application/json' \
+	//   -b session=abc \
+	//   -H 'Content-Type: application/json' \
+	//   --data-raw '{"name":"O'\''Brien"}'
+}
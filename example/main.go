@@ -9,7 +9,8 @@ import (
 )
 
 func main() {
-	fmt.Println("Frisby!\n")
+	fmt.Println("Frisby!")
+	fmt.Println()
 
 	frisby.Create("Test GET Go homepage").
 		Get("http://golang.org").
@@ -1,6 +1,8 @@
 package frisby
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -14,114 +16,171 @@ type ExpectFunc func(F *Frisby) (bool, string)
 
 // Expect Checks according to the given function, which allows you to describe any kind of assertion.
 func (F *Frisby) Expect(foo ExpectFunc) *Frisby {
-	Global.NumAsserts++
-	if ok, err_str := foo(F); !ok {
-		F.AddError(err_str)
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	err_str := ""
+	if ok, msg := foo(F); !ok {
+		err_str = msg
+	}
+	F.addAssertion("expect", "", nil, nil, err_str)
+	return F
+}
+
+// Assert records an assertion of the given kind, which fails with every non-empty err_str
+//
+// This allows packages like openapi to add their own kinds of expectations.
+func (F *Frisby) Assert(kind, path string, expected, actual interface{}, err_strs ...string) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
 	}
+	F.countAssert()
+	F.addAssertion(kind, path, expected, actual, err_strs...)
 	return F
 }
 
 // Checks the response status code
 func (F *Frisby) ExpectStatus(code int) *Frisby {
-	Global.NumAsserts++
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
 	status := F.Resp.StatusCode
+	err_str := ""
 	if status != code {
-		err_str := fmt.Sprintf("Expected Status %d, but got %d: %q", code, status, F.Resp.Status)
-		F.AddError(err_str)
+		err_str = fmt.Sprintf("Expected Status %d, but got %d: %q", code, status, F.Resp.Status)
 	}
+	F.addAssertion("status", "", code, status, err_str)
 	return F
 }
 
 // Checks for header and if values match
 func (F *Frisby) ExpectHeader(key, value string) *Frisby {
-	Global.NumAsserts++
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
 	chk_val := F.Resp.Header.Get(key)
+	err_str := ""
 	if chk_val == "" {
-		err_str := fmt.Sprintf("Expected Header %q, but it was missing", key)
-		F.AddError(err_str)
+		err_str = fmt.Sprintf("Expected Header %q, but it was missing", key)
 	} else if chk_val != value {
-		err_str := fmt.Sprintf("Expected Header %q to be %q, but got %q", key, value, chk_val)
-		F.AddError(err_str)
+		err_str = fmt.Sprintf("Expected Header %q to be %q, but got %q", key, value, chk_val)
 	}
+	F.addAssertion("header", key, value, chk_val, err_str)
 	return F
 }
 
 // Checks the response body for the given string
 func (F *Frisby) ExpectContent(content string) *Frisby {
-	Global.NumAsserts++
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
 	text, err := F.Resp.Text()
 	if err != nil {
-		F.AddError(err.Error())
+		F.addAssertion("content", "", content, nil, err.Error())
 		return F
 	}
 	contains := strings.Contains(text, content)
+	err_str := ""
 	if !contains {
-		err_str := fmt.Sprintf("Expected Body to contain %q, but it was missing", content)
-		F.AddError(err_str)
+		err_str = fmt.Sprintf("Expected Body to contain %q, but it was missing", content)
 	}
+	F.addAssertion("content", "", content, nil, err_str)
 	return F
 }
 
 // ExpectJson uses the reflect.DeepEqual to compare the response
 // JSON and the supplied JSON for structural and value equality
 //
-// path can be a dot joined field names.
+// path can be a dot joined field names, a JSON Pointer or a JSONPath,
+// see FindJson() for details. Every value matched by path must be equal.
 // ex:  'path.to.subobject.field'
 func (F *Frisby) ExpectJson(path string, value interface{}) *Frisby {
-	Global.NumAsserts++
-	simp_json, err := F.Resp.Json()
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	matches, err := F.responseJson(path)
 	if err != nil {
-		F.AddError(err.Error())
+		F.addAssertion("json", path, value, nil, err.Error())
 		return F
 	}
 
-	if path != "" {
-		// Loop over each path item and progress down the json path.
-		path_items := strings.Split(path, Global.PathSeparator)
-		for _, segment := range path_items {
-			processed := false
-			// If the path segment is an integer, and we're at an array, access the index.
-			index, err := strconv.Atoi(segment)
-			if err == nil {
-				if _, err := simp_json.Array(); err == nil {
-					simp_json = simp_json.GetIndex(index)
-					processed = true
-				}
-			}
-
-			if !processed {
-				simp_json = simp_json.Get(segment)
-			}
-		}
-	}
-	json := simp_json.Interface()
-
-	equal := false
-	switch reflect.ValueOf(value).Kind() {
-	case reflect.Int:
-		val, err := simp_json.Int()
+	for _, json := range matches {
+		equal, err := jsonEqual(value, json)
 		if err != nil {
-			F.AddError(err.Error())
+			F.addAssertion("json", path, value, jsonActual(matches), err.Error())
 			return F
-		} else {
-			equal = (val == value.(int))
 		}
-	case reflect.Float64:
-		val, err := simp_json.Float64()
-		if err != nil {
-			F.AddError(err.Error())
+		if !equal {
+			err_str := fmt.Sprintf("ExpectJson equality test failed for %q, got value: %v", path, json)
+			F.addAssertion("json", path, value, jsonActual(matches), err_str)
 			return F
-		} else {
-			equal = (val == value.(float64))
 		}
-	default:
-		equal = reflect.DeepEqual(value, json)
 	}
+	F.addAssertion("json", path, value, jsonActual(matches))
 
-	if !equal {
-		err_str := fmt.Sprintf("ExpectJson equality test failed for %q, got value: %v", path, json)
-		F.AddError(err_str)
+	return F
+}
+
+// ExpectJsonAny checks if any of the values matched by path
+// in the response JSON is equal to the supplied JSON
+//
+// path can be a dot joined field names, a JSON Pointer or a JSONPath,
+// see FindJson() for details.
+// ex:  '$.items[*].id'
+func (F *Frisby) ExpectJsonAny(path string, value interface{}) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
 	}
+	F.countAssert()
+	matches, err := F.responseJson(path)
+	if err != nil {
+		F.addAssertion("json_any", path, value, nil, err.Error())
+		return F
+	}
+
+	for _, json := range matches {
+		if equal, _ := jsonEqual(value, json); equal {
+			F.addAssertion("json_any", path, value, jsonActual(matches))
+			return F
+		}
+	}
+	err_str := fmt.Sprintf("ExpectJsonAny equality test failed for %q, got values: %v", path, matches)
+	F.addAssertion("json_any", path, value, jsonActual(matches), err_str)
+
+	return F
+}
+
+// ExpectJsonCount checks the number of values matched by path in the response JSON
+//
+// path can be a dot joined field names, a JSON Pointer or a JSONPath,
+// see FindJson() for details.
+// ex:  '$.items[?(@.price < 10)]'
+func (F *Frisby) ExpectJsonCount(path string, count int) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	simp_json, err := F.Resp.Json()
+	if err != nil {
+		F.addAssertion("json_count", path, count, nil, err.Error())
+		return F
+	}
+	matches, err := findJson(simp_json.Interface(), path, F.Suite.PathSeparator)
+	if err != nil {
+		F.addAssertion("json_count", path, count, nil, err.Error())
+		return F
+	}
+
+	err_str := ""
+	if len(matches) != count {
+		err_str = fmt.Sprintf("Expect Json %q to match %d values, but got %d", path, count, len(matches))
+	}
+	F.addAssertion("json_count", path, count, len(matches), err_str)
 
 	return F
 }
@@ -129,28 +188,29 @@ func (F *Frisby) ExpectJson(path string, value interface{}) *Frisby {
 // ExpectJsonType checks if the types of the response
 // JSON and the supplied JSON match
 //
-// path can be a dot joined field names.
+// path can be a dot joined field names, a JSON Pointer or a JSONPath,
+// see FindJson() for details. Every value matched by path must have the type.
 // ex:  'path.to.subobject.field'
 func (F *Frisby) ExpectJsonType(path string, val_type reflect.Kind) *Frisby {
-	Global.NumAsserts++
-	json, err := F.Resp.Json()
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	matches, err := F.responseJson(path)
 	if err != nil {
-		F.AddError(err.Error())
+		F.addAssertion("json_type", path, val_type.String(), nil, err.Error())
 		return F
 	}
 
-	if path != "" {
-		path_items := strings.Split(path, Global.PathSeparator)
-		json = json.GetPath(path_items...)
-	}
-
-	json_json := json.Interface()
-
-	json_val := reflect.ValueOf(json_json)
-	if val_type != json_val.Kind() {
-		err_str := fmt.Sprintf("Expect Json %q type to be %q, but got %T", path, val_type, json_json)
-		F.AddError(err_str)
+	for _, json_json := range matches {
+		json_val := reflect.ValueOf(json_json)
+		if val_type != json_val.Kind() {
+			err_str := fmt.Sprintf("Expect Json %q type to be %q, but got %T", path, val_type, json_json)
+			F.addAssertion("json_type", path, val_type.String(), json_val.Kind().String(), err_str)
+			return F
+		}
 	}
+	F.addAssertion("json_type", path, val_type.String(), val_type.String())
 
 	return F
 }
@@ -158,34 +218,152 @@ func (F *Frisby) ExpectJsonType(path string, val_type reflect.Kind) *Frisby {
 // ExpectJsonLength checks if the JSON at path
 // is an array and has the correct length
 //
-// path can be a dot joined field names.
+// path can be a dot joined field names, a JSON Pointer or a JSONPath,
+// see FindJson() for details. Every value matched by path must have the length.
 // ex:  'path.to.subobject.field'
 func (F *Frisby) ExpectJsonLength(path string, length int) *Frisby {
-	Global.NumAsserts++
-	json, err := F.Resp.Json()
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	matches, err := F.responseJson(path)
 	if err != nil {
-		F.AddError(err.Error())
+		F.addAssertion("json_length", path, length, nil, err.Error())
 		return F
 	}
 
-	if path != "" {
-		path_items := strings.Split(path, Global.PathSeparator)
-		json = json.GetPath(path_items...)
+	for _, json := range matches {
+		ary, ok := json.([]interface{})
+		if !ok {
+			err_str := fmt.Sprintf("Expect Json %q to be an array, but got %T", path, json)
+			F.addAssertion("json_length", path, length, nil, err_str)
+			return F
+		}
+		L := len(ary)
+
+		if L != length {
+			err_str := fmt.Sprintf("Expect length to be %d, but got %d", length, L)
+			F.addAssertion("json_length", path, length, L, err_str)
+			return F
+		}
 	}
+	F.addAssertion("json_length", path, length, length)
 
-	ary, err := json.Array()
+	return F
+}
+
+// responseJson returns the values in the response JSON matched by path,
+// or an error if there are none
+func (F *Frisby) responseJson(path string) ([]interface{}, error) {
+	simp_json, err := F.Resp.Json()
 	if err != nil {
-		F.AddError(err.Error())
-		return F
+		return nil, err
+	}
+	matches, err := findJson(simp_json.Interface(), path, F.Suite.PathSeparator)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("Expected Json %q, but it was missing", path)
 	}
-	L := len(ary)
+	return matches, nil
+}
 
-	if L != length {
-		err_str := fmt.Sprintf("Expect length to be %d, but got %d", length, L)
-		F.AddError(err_str)
+// jsonEqual compares a Go value with a value of the response JSON,
+// which holds its numbers as json.Number
+func jsonEqual(value, actual interface{}) (bool, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, ok := actual.(json.Number)
+		if !ok {
+			return false, errors.New("invalid value type")
+		}
+		val, err := num.Int64()
+		if err != nil {
+			return false, err
+		}
+		return val == v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, ok := actual.(json.Number)
+		if !ok {
+			return false, errors.New("invalid value type")
+		}
+		val, err := strconv.ParseUint(num.String(), 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return val == v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		num, ok := actual.(json.Number)
+		if !ok {
+			return false, errors.New("invalid value type")
+		}
+		val, err := num.Float64()
+		if err != nil {
+			return false, err
+		}
+		if v.Kind() == reflect.Float32 {
+			// compared at the precision of the expected value
+			return float32(val) == float32(v.Float()), nil
+		}
+		return val == v.Float(), nil
 	}
+	return reflect.DeepEqual(value, actual), nil
+}
 
-	return F
+// jsonActual is the single value matched, or all of the values
+func jsonActual(matches []interface{}) interface{} {
+	if len(matches) == 1 {
+		return matches[0]
+	}
+	return matches
+}
+
+// Assertion is the result of a single Expect call
+//
+// Kind is the kind of expectation, like "status" for ExpectStatus()
+// and "json" for ExpectJson(). Path is the JSON path or header key.
+type Assertion struct {
+	Kind     string      `json:"kind"`
+	Path     string      `json:"path,omitempty"`
+	Expected interface{} `json:"expected,omitempty"`
+	Actual   interface{} `json:"actual,omitempty"`
+	Passed   bool        `json:"passed"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// addAssertion records the result of an expectation,
+// adding every non-empty err_str as an error
+func (F *Frisby) addAssertion(kind, path string, expected, actual interface{}, err_strs ...string) {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	errs := make([]string, 0, len(err_strs))
+	for _, err_str := range err_strs {
+		if err_str != "" {
+			errs = append(errs, err_str)
+		}
+	}
+
+	assertion := Assertion{
+		Kind:     kind,
+		Path:     path,
+		Expected: expected,
+		Actual:   actual,
+		Passed:   len(errs) == 0,
+		Error:    strings.Join(errs, "; "),
+	}
+	if F.attempt != nil {
+		F.attempt.asserts = append(F.attempt.asserts, assertion)
+	} else {
+		F.Asserts = append(F.Asserts, assertion)
+		F.Suite.streamAssertion(F, assertion)
+	}
+
+	for _, err_str := range errs {
+		F.AddError(err_str)
+	}
 }
 
 // function type used as argument to AfterContent()
@@ -256,6 +434,9 @@ func (F *Frisby) PrintReport() *Frisby {
 		for _, e := range F.Errs {
 			fmt.Println("        - ", e)
 		}
+		if F.Suite.PrintCurlOnFail {
+			fmt.Println(indent(F.AsCurl(), "        "))
+		}
 	}
 
 	return F
@@ -272,6 +453,9 @@ func (F *Frisby) PrintGoTestReport() *Frisby {
 		for _, e := range F.Errs {
 			fmt.Println("	", e)
 		}
+		if F.Suite.PrintCurlOnFail {
+			fmt.Println(indent(F.AsCurl(), "	"))
+		}
 	}
 	return F
 }
@@ -1,18 +1,23 @@
 package frisby
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"testing"
 	"time"
 
 	"github.com/mozillazg/request"
 )
 
-var Global global_data
-
 const defaultFileKey = "file"
 
 type Frisby struct {
@@ -20,33 +25,85 @@ type Frisby struct {
 	Url    string
 	Method string
 
+	// the Suite which created the Frisby object and
+	// keeps its counters, errors and variables
+	Suite *Suite
+
 	Req           *request.Request
 	Resp          *request.Response
 	Errs          []error
+	Asserts       []Assertion
 	ExecutionTime float64
+
+	// set by CreateT() or WithT() to report through go test
+	T *testing.T
+
+	// copied from the Suite by Create()
+	SendOptions
+
+	// breakdown of the request time, set by Send()
+	Timing Timing
+
+	// every request sent by the last Send(), redirects
+	// and answers to auth challenges included
+	Requests []SentRequest
+
+	// set by Tag() to check the Frisby object against Suite budgets
+	Tags []string
+
+	// number of times Eventually() or Retry() ran their chain
+	Attempts int
+
+	// raw body set by SetBody(), SetBodyString() or SetBodyReader(),
+	// and whether it is text with placeholders
+	body       []byte
+	bodyReader io.Reader
+	bodyText   bool
+
+	// collects the results of the running Eventually() or Retry() attempt
+	attempt *attempt
+
+	// the transport cloned by the TLS options
+	tlsTransport *http.Transport
 }
 
 // Creates a new Frisby object with the given name.
 //
 // The given name will be used if you call PrintReport()
 func Create(name string) *Frisby {
+	return Global.Create(name)
+}
+
+// Creates a new Frisby object with the given name,
+// inheriting the settings of the Suite
+//
+// The given name will be used if you call PrintReport()
+func (G *Suite) Create(name string) *Frisby {
+	F := G.newFrisby(name)
+	G.addFrisby(F)
+	return F
+}
+
+// newFrisby creates a Frisby object without adding it to Frisbies
+func (G *Suite) newFrisby(name string) *Frisby {
 	F := new(Frisby)
 	F.Name = name
-	F.Req = request.NewRequest(new(http.Client))
+	F.Suite = G
 	F.Errs = make([]error, 0)
 
-	// copy in global settings
-	F.Req.BasicAuth = Global.Req.BasicAuth
-	F.Req.Proxy = Global.Req.Proxy
-	F.SetHeaders(Global.Req.Headers)
-	F.SetCookies(Global.Req.Cookies)
-	F.SetDatas(Global.Req.Data)
-	F.SetParams(Global.Req.Params)
-	F.Req.Json = Global.Req.Json
-	F.Req.Files = append(F.Req.Files, Global.Req.Files...)
-
-	// initialize request
-	F.Req.Params = make(map[string]string)
+	// copy in suite settings, the request package gives the
+	// client a new cookie jar unless the Suite has a Session
+	client := *G.Req.Client
+	F.Req = request.NewRequest(&client)
+	F.Req.BasicAuth = G.Req.BasicAuth
+	F.Req.Proxy = G.Req.Proxy
+	F.SetHeaders(G.Req.Headers)
+	F.SetCookies(G.Req.Cookies)
+	F.SetDatas(G.Req.Data)
+	F.SetParams(G.Req.Params)
+	F.Req.Json = G.Req.Json
+	F.Req.Files = append(F.Req.Files, G.Req.Files...)
+	F.SendOptions = G.SendOptions
 
 	return F
 }
@@ -100,9 +157,16 @@ func (F *Frisby) Options(url string) *Frisby {
 	return F
 }
 
+// Set the HTTP method, any method like PROPFIND or PURGE, for the given URL
+func (F *Frisby) Do(method, url string) *Frisby {
+	F.Method = method
+	F.Url = url
+	return F
+}
+
 // Set BasicAuth values for the coming request
 func (F *Frisby) BasicAuth(user, passwd string) *Frisby {
-	F.Req.BasicAuth = request.BasicAuth{user, passwd}
+	F.Req.BasicAuth = request.BasicAuth{Username: user, Password: passwd}
 	return F
 }
 
@@ -154,6 +218,7 @@ func (F *Frisby) SetCookies(cookies map[string]string) *Frisby {
 
 // Set a Form data for the coming request
 func (F *Frisby) SetData(key, value string) *Frisby {
+	F.clearRawBody()
 	if F.Req.Data == nil {
 		F.Req.Data = make(map[string]string)
 	}
@@ -163,6 +228,9 @@ func (F *Frisby) SetData(key, value string) *Frisby {
 
 // Set several Form data for the coming request
 func (F *Frisby) SetDatas(datas map[string]string) *Frisby {
+	if len(datas) > 0 {
+		F.clearRawBody()
+	}
 	if F.Req.Data == nil {
 		F.Req.Data = make(map[string]string)
 	}
@@ -183,7 +251,8 @@ func (F *Frisby) SetParam(key, value string) *Frisby {
 
 // Set several url Param for the coming request
 func (F *Frisby) SetParams(params map[string]string) *Frisby {
-	if F.Req.Params == nil {
+	// Params stays nil without any, so no empty query is added
+	if F.Req.Params == nil && len(params) > 0 {
 		F.Req.Params = make(map[string]string)
 	}
 	for key, value := range params {
@@ -194,16 +263,70 @@ func (F *Frisby) SetParams(params map[string]string) *Frisby {
 
 // Set the JSON body for the coming request
 func (F *Frisby) SetJson(json interface{}) *Frisby {
+	F.clearRawBody()
 	F.Req.Json = json
 	return F
 }
 
+// Set the raw body for the coming request, instead of any Json or Form data
+//
+// The Content-Type is not set, use SetHeader() or SetBodyString().
+func (F *Frisby) SetBody(body []byte) *Frisby {
+	F.clearBody()
+	if body == nil {
+		body = []byte{}
+	}
+	F.body = body
+	return F
+}
+
+// Set the raw text body and its Content-Type for the coming request,
+// instead of any Json or Form data
+//
+// Placeholders in the body are filled in by Send(), like in Json.
+// ex:  SetBodyString("application/xml", "<order id=\"{{id}}\"/>")
+func (F *Frisby) SetBodyString(content_type, body string) *Frisby {
+	F.SetBody([]byte(body))
+	F.bodyText = true
+	if content_type != "" {
+		F.SetHeader("Content-Type", content_type)
+	}
+	return F
+}
+
+// Set a reader as the raw body for the coming request, instead of any Json or Form data
+//
+// The reader is streamed and can only be sent once, so requests needing
+// their body again, to retry or to answer auth challenges, should use SetBody().
+func (F *Frisby) SetBodyReader(reader io.Reader) *Frisby {
+	F.clearBody()
+	F.bodyReader = reader
+	return F
+}
+
+// clearBody removes any body of the coming request, as they can not be combined
+func (F *Frisby) clearBody() {
+	F.clearRawBody()
+	F.Req.Json = nil
+	F.Req.Data = nil
+	F.Req.Files = nil
+}
+
+// clearRawBody removes the raw body when a Json or Form data body is set
+func (F *Frisby) clearRawBody() {
+	F.body = nil
+	F.bodyReader = nil
+	F.bodyText = false
+	F.Req.Body = nil
+}
+
 // Add a file to the Form data for the coming request
 func (F *Frisby) AddFile(filename string) *Frisby {
 	file, err := os.Open(filename)
 	if err != nil {
-		F.Errs = append(F.Errs, err)
+		F.appendError(err)
 	} else {
+		F.clearRawBody()
 		fileField := request.FileField{
 			FieldName: defaultFileKey,
 			FileName:  filepath.Base(filename),
@@ -217,8 +340,9 @@ func (F *Frisby) AddFile(filename string) *Frisby {
 func (F *Frisby) AddFileByKey(key, filename string) *Frisby {
 	file, err := os.Open(filename)
 	if err != nil {
-		F.Errs = append(F.Errs, err)
+		F.appendError(err)
 	} else {
+		F.clearRawBody()
 		if len(key) == 0 {
 			key = defaultFileKey
 		}
@@ -233,50 +357,245 @@ func (F *Frisby) AddFileByKey(key, filename string) *Frisby {
 
 // Send the actual request to the URL
 func (F *Frisby) Send() *Frisby {
-	Global.NumRequest++
-	if Global.PrintProgressName {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.Suite.countRequest()
+	if F.Suite.PrintProgressName {
 		fmt.Println(F.Name)
-	} else if Global.PrintProgressDot {
+	} else if F.Suite.PrintProgressDot {
 		fmt.Printf("")
 	}
 
+	F.interpolate()
+
+	if F.Req.Proxy != "" {
+		restore, err := F.proxy()
+		if err != nil {
+			F.sendFailed(err)
+			return F
+		}
+		defer restore()
+	}
+
+	// the transport of the client, before any wrapping
+	base := F.Req.Client.Transport
+
+	timer, restore := F.trace()
+	defer restore()
+
+	if F.Cassette != nil {
+		cassette := F.Cassette
+		defer F.wrapTransport(func(next http.RoundTripper) http.RoundTripper {
+			return &cassetteTransport{cassette: cassette, next: next}
+		})()
+	}
+
+	var rec *recorder
+	if F.Validator != nil || F.Har != nil {
+		var restore func()
+		rec, restore = F.record()
+		defer restore()
+	}
+
+	// wrapped last so the recorder gets the request as sent, and
+	// the token and the answers to Digest challenges are signed
+	if F.Signer != nil {
+		defer F.sign()()
+	}
+	if F.Auth != nil {
+		defer F.authorize(base)()
+	}
+	if F.Digest != nil {
+		defer F.digest()()
+	}
+
+	// a new reader on each Send(), so retries send the body again
+	for _, file := range F.Req.Files {
+		if seeker, ok := file.File.(io.Seeker); ok {
+			seeker.Seek(0, io.SeekStart)
+		}
+	}
+	if F.body != nil {
+		F.Req.Body = bytes.NewReader(F.body)
+	} else if F.bodyReader != nil {
+		F.Req.Body = F.bodyReader
+	}
+
 	start := time.Now()
 
-	var err error
-	switch F.Method {
-	case "GET":
-		F.Resp, err = F.Req.Get(F.Url)
-	case "POST":
-		F.Resp, err = F.Req.Post(F.Url)
-	case "PUT":
-		F.Resp, err = F.Req.Put(F.Url)
-	case "PATCH":
-		F.Resp, err = F.Req.Patch(F.Url)
-	case "DELETE":
-		F.Resp, err = F.Req.Delete(F.Url)
-	case "HEAD":
-		F.Resp, err = F.Req.Head(F.Url)
-	case "OPTIONS":
-		F.Resp, err = F.Req.Options(F.Url)
+	F.Resp = nil
+	req, err := F.newRequest()
+	if err == nil {
+		var resp *http.Response
+		resp, err = F.do(req)
+		if err == nil {
+			F.Resp = &request.Response{Response: resp}
+			// read the body now, so the timing covers its transfer
+			F.Resp.Content()
+		}
 	}
+	F.Timing, F.Requests = timer.stop()
 
 	F.ExecutionTime = time.Since(start).Seconds()
+	F.Suite.countTime(F.ExecutionTime)
+	F.Suite.streamRequest(F, err)
+	if F.Har != nil {
+		F.Har.add(F, rec, start, err)
+	}
 
 	if err != nil {
-		F.Errs = append(F.Errs, err)
+		F.sendFailed(err)
+	} else if F.Validator != nil {
+		// the last request, after redirects and challenges
+		if trip := rec.last(); trip != nil {
+			F.Validator.Validate(F, trip.req, trip.reqBody)
+		}
 	}
 
 	return F
 }
 
+// newRequest builds the request to send from F.Req, the way the
+// request package does for its GET, POST, ... methods, for any method
+func (F *Frisby) newRequest() (*http.Request, error) {
+	var body io.Reader
+	content_type := ""
+	switch {
+	case F.Req.Body != nil:
+		body = F.Req.Body
+	case len(F.Req.Files) > 0:
+		buffer := new(bytes.Buffer)
+		writer := multipart.NewWriter(buffer)
+		for _, file := range F.Req.Files {
+			part, err := writer.CreateFormFile(file.FieldName, file.FileName)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := io.Copy(part, file.File); err != nil {
+				return nil, err
+			}
+		}
+		for _, key := range sortedKeys(F.Req.Data) {
+			writer.WriteField(key, F.Req.Data[key])
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		body = buffer
+		content_type = writer.FormDataContentType()
+	case F.Req.Json != nil:
+		data, err := json.Marshal(F.Req.Json)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		content_type = request.DefaultJsonType
+	case len(F.Req.Data) > 0:
+		values := url.Values{}
+		for key, value := range F.Req.Data {
+			values.Set(key, value)
+		}
+		body = strings.NewReader(values.Encode())
+		content_type = request.DefaultContentType
+	}
+
+	req, err := http.NewRequest(F.method(), F.requestUrl(), body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range request.DefaultHeaders {
+		if _, ok := F.Req.Headers[key]; !ok {
+			req.Header.Set(key, value)
+		}
+	}
+	for key, value := range F.Req.Headers {
+		req.Header.Set(key, value)
+	}
+	if _, ok := F.Req.Headers["Content-Type"]; !ok && content_type != "" {
+		req.Header.Set("Content-Type", content_type)
+	}
+
+	// like the request package, the cookies are kept in the jar
+	// for the coming requests of the client
+	if len(F.Req.Cookies) > 0 {
+		cookies := make([]*http.Cookie, 0, len(F.Req.Cookies))
+		for _, key := range sortedKeys(F.Req.Cookies) {
+			cookies = append(cookies, &http.Cookie{Name: key, Value: F.Req.Cookies[key]})
+		}
+		if F.Req.Client.Jar != nil {
+			F.Req.Client.Jar.SetCookies(req.URL, cookies)
+		} else {
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+		}
+	}
+
+	if F.Req.BasicAuth.Username != "" {
+		req.SetBasicAuth(F.Req.BasicAuth.Username, F.Req.BasicAuth.Password)
+	}
+	return req, nil
+}
+
+// do sends req with the client, calling the request package Hooks around it
+func (F *Frisby) do(req *http.Request) (*http.Response, error) {
+	for _, hook := range F.Req.Hooks {
+		if resp, err := hook.BeforeRequest(req); resp != nil || err != nil {
+			return resp, err
+		}
+	}
+	resp, err := F.Req.Client.Do(req)
+	for _, hook := range F.Req.Hooks {
+		if new_resp, new_err := hook.AfterRequest(req, resp, err); new_resp != nil || new_err != nil {
+			if new_resp != nil {
+				resp = new_resp
+			}
+			if new_err != nil {
+				err = new_err
+			}
+			break
+		}
+	}
+	return resp, err
+}
+
+// sendFailed records the error of Send(), stopping the test when bound to one
+func (F *Frisby) sendFailed(err error) {
+	if F.attempt != nil {
+		F.attempt.sendErr = err
+		panic(attemptAborted{})
+	}
+	F.appendError(err)
+	if F.T != nil {
+		F.T.Helper()
+		F.T.Fatalf("Send %s %q failed: %v", F.Method, F.Url, err)
+	}
+}
+
 // Manually add an error, if you need to
 func (F *Frisby) AddError(err_str string) *Frisby {
-	err := errors.New(err_str)
-	F.Errs = append(F.Errs, err)
-	Global.AddError(F.Name, err_str)
+	if F.attempt != nil {
+		F.attempt.errs = append(F.attempt.errs, err_str)
+		return F
+	}
+	if F.T != nil {
+		F.T.Helper()
+		F.T.Error(err_str)
+	}
+	F.appendError(errors.New(err_str))
+	F.Suite.AddError(F.Name, err_str)
 	return F
 }
 
+// appendError adds err to Errs, counting the Frisby object as failed on its first error
+func (F *Frisby) appendError(err error) {
+	if len(F.Errs) == 0 {
+		F.Suite.countFailed()
+	}
+	F.Errs = append(F.Errs, err)
+}
+
 // Get the most recent error for the Frisby object
 //
 // This function should be called last
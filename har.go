@@ -0,0 +1,484 @@
+package frisby
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Har is an HTTP Archive (HAR 1.2) of the requests sent and the responses
+// received, which can be opened in browser devtools
+//
+// Set it with Global.SetHar() or Frisby.SetHar() and Send() adds an entry
+// for each round trip, redirects and auth challenges included, after its
+// Redactors ran. Call Save() to write the file.
+type Har struct {
+	Filename string `json:"-"`
+
+	// run on each entry, in order, before it is added
+	Redactors []HarRedactor `json:"-"`
+
+	Log HarLog `json:"log"`
+
+	mu sync.Mutex
+}
+
+// HarRedactor changes an entry before it is added to a Har,
+// to hide secrets like tokens or passwords
+type HarRedactor func(E *HarEntry)
+
+// the value which replaces redacted secrets
+const HarRedacted = "REDACTED"
+
+type HarLog struct {
+	Version string      `json:"version"`
+	Creator HarCreator  `json:"creator"`
+	Entries []*HarEntry `json:"entries"`
+}
+
+type HarCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HarEntry is a request and its response
+//
+// Comment holds the name of the Frisby object, and the error of Send() if any.
+type HarEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HarRequest  `json:"request"`
+	Response        HarResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HarTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type HarRequest struct {
+	Method      string         `json:"method"`
+	Url         string         `json:"url"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []HarCookie    `json:"cookies"`
+	Headers     []HarNameValue `json:"headers"`
+	QueryString []HarNameValue `json:"queryString"`
+	PostData    *HarPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HarResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []HarCookie    `json:"cookies"`
+	Headers     []HarNameValue `json:"headers"`
+	Content     HarContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HarNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HarCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HttpOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+type HarPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []HarNameValue `json:"params,omitempty"`
+	Text     string         `json:"text"`
+	Comment  string         `json:"comment,omitempty"`
+}
+
+// HarContent is a response body, base64 encoded when it is not valid UTF-8
+type HarContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HarTimings are in milliseconds, -1 when they do not apply
+type HarTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// NewHar creates an empty Har, written to filename by Save()
+func NewHar(filename string, redactors ...HarRedactor) *Har {
+	return &Har{
+		Filename:  filename,
+		Redactors: redactors,
+		Log: HarLog{
+			Version: "1.2",
+			Creator: HarCreator{Name: "frisby", Version: "1.0"},
+			Entries: make([]*HarEntry, 0),
+		},
+	}
+}
+
+// Add redactors run on each coming entry
+func (H *Har) Redact(redactors ...HarRedactor) *Har {
+	H.mu.Lock()
+	defer H.mu.Unlock()
+	H.Redactors = append(H.Redactors, redactors...)
+	return H
+}
+
+// Entries returns a copy of the entries added so far
+func (H *Har) Entries() []*HarEntry {
+	H.mu.Lock()
+	defer H.mu.Unlock()
+	return append([]*HarEntry(nil), H.Log.Entries...)
+}
+
+// Save writes the archive to its file
+func (H *Har) Save() error {
+	H.mu.Lock()
+	defer H.mu.Unlock()
+	data, err := json.MarshalIndent(H, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(H.Filename); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(H.Filename, append(data, '\n'), 0644)
+}
+
+// add makes an entry for each round trip of the last Send() of F, like
+// redirects and Digest challenges, from the requests kept by rec
+//
+// The Send() started at start, and failed with err if not nil.
+func (H *Har) add(F *Frisby, rec *recorder, start time.Time, err error) {
+	entries := make([]*HarEntry, 0, 1)
+	if rec != nil {
+		for _, trip := range rec.trips {
+			entries = append(entries, newHarEntry(F, trip))
+		}
+	}
+	if len(entries) == 0 {
+		// the request failed before it was sent
+		entries = append(entries, newHarEntry(F, &roundTrip{start: start}))
+	}
+
+	// the last round trip has the full Timing of the request
+	last := entries[len(entries)-1]
+	last.Timings = harTimings(F.Timing)
+	last.Time = float64(F.Timing.Total) / float64(time.Millisecond)
+	if err != nil {
+		last.Comment += ": " + err.Error()
+	}
+
+	H.mu.Lock()
+	defer H.mu.Unlock()
+	for _, E := range entries {
+		for _, redact := range H.Redactors {
+			redact(E)
+		}
+		H.Log.Entries = append(H.Log.Entries, E)
+	}
+}
+
+// newHarEntry makes the entry of a round trip of F
+func newHarEntry(F *Frisby, trip *roundTrip) *HarEntry {
+	E := &HarEntry{StartedDateTime: trip.start, Comment: F.Name}
+
+	E.Request = HarRequest{
+		Method:      F.method(),
+		Url:         F.requestUrl(),
+		HttpVersion: "HTTP/1.1",
+		Cookies:     make([]HarCookie, 0),
+		Headers:     make([]HarNameValue, 0),
+		QueryString: make([]HarNameValue, 0),
+		HeadersSize: -1,
+	}
+	if req := trip.req; req != nil {
+		E.Request.Method = req.Method
+		E.Request.Url = req.URL.String()
+		E.Request.Headers = harHeaders(req.Header)
+		for _, cookie := range req.Cookies() {
+			E.Request.Cookies = append(E.Request.Cookies, HarCookie{Name: cookie.Name, Value: cookie.Value})
+		}
+		query := req.URL.Query()
+		for _, key := range sortedKeys(query) {
+			for _, value := range query[key] {
+				E.Request.QueryString = append(E.Request.QueryString, HarNameValue{key, value})
+			}
+		}
+		E.Request.BodySize = len(trip.reqBody)
+		if len(trip.reqBody) > 0 {
+			E.Request.PostData = harPostData(req.Header.Get("Content-Type"), trip.reqBody)
+		}
+	}
+
+	E.Response = HarResponse{
+		Cookies:     make([]HarCookie, 0),
+		Headers:     make([]HarNameValue, 0),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if resp := trip.resp; resp != nil {
+		content := trip.body
+		E.Request.HttpVersion = resp.Proto
+		E.Response.Status = resp.StatusCode
+		E.Response.StatusText = http.StatusText(resp.StatusCode)
+		E.Response.HttpVersion = resp.Proto
+		E.Response.Headers = harHeaders(resp.Header)
+		for _, cookie := range resp.Cookies() {
+			har_cookie := HarCookie{
+				Name:     cookie.Name,
+				Value:    cookie.Value,
+				Path:     cookie.Path,
+				Domain:   cookie.Domain,
+				HttpOnly: cookie.HttpOnly,
+				Secure:   cookie.Secure,
+			}
+			if !cookie.Expires.IsZero() {
+				expires := cookie.Expires
+				har_cookie.Expires = &expires
+			}
+			E.Response.Cookies = append(E.Response.Cookies, har_cookie)
+		}
+		E.Response.Content = HarContent{Size: len(content), MimeType: resp.Header.Get("Content-Type")}
+		if utf8.Valid(content) {
+			E.Response.Content.Text = string(content)
+		} else {
+			E.Response.Content.Text = base64.StdEncoding.EncodeToString(content)
+			E.Response.Content.Encoding = "base64"
+		}
+		E.Response.RedirectURL = resp.Header.Get("Location")
+		E.Response.BodySize = len(content)
+	}
+
+	E.Timings = harTimings(trip.timing)
+	E.Time = float64(trip.timing.Total) / float64(time.Millisecond)
+	return E
+}
+
+// harHeaders lists the headers sorted by name
+func harHeaders(header http.Header) []HarNameValue {
+	headers := make([]HarNameValue, 0, len(header))
+	for _, key := range sortedKeys(header) {
+		for _, value := range header[key] {
+			headers = append(headers, HarNameValue{key, value})
+		}
+	}
+	return headers
+}
+
+// harPostData makes the post data of a request body, whose text is
+// left out with a comment when it is binary, as HAR has no encoding for it
+func harPostData(content_type string, body []byte) *HarPostData {
+	post := &HarPostData{MimeType: content_type, Text: string(body)}
+	if !utf8.Valid(body) {
+		post.Text = ""
+		post.Comment = fmt.Sprintf("binary body of %d bytes omitted", len(body))
+	}
+	if media_type, _, _ := mime.ParseMediaType(content_type); media_type == "application/x-www-form-urlencoded" {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for _, key := range sortedKeys(values) {
+				for _, value := range values[key] {
+					post.Params = append(post.Params, HarNameValue{key, value})
+				}
+			}
+		}
+	}
+	return post
+}
+
+// harTimings splits a Timing into the HAR phases, where connect includes ssl
+func harTimings(T Timing) HarTimings {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	timings := HarTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	wait := T.TTFB
+	if !T.Reused {
+		if T.DNS > 0 {
+			timings.DNS = ms(T.DNS)
+		}
+		if T.Connect > 0 {
+			timings.Connect = ms(T.Connect + T.TLS)
+		}
+		if T.TLS > 0 {
+			timings.SSL = ms(T.TLS)
+		}
+		wait -= T.DNS + T.Connect + T.TLS
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	timings.Wait = ms(wait)
+	timings.Receive = ms(T.Transfer)
+	return timings
+}
+
+// RedactHeaders replaces the values of the named request and response headers
+//
+// Redacting Cookie or Set-Cookie also replaces the values of
+// the request or response cookies.
+func RedactHeaders(names ...string) HarRedactor {
+	return func(E *HarEntry) {
+		for _, name := range names {
+			redactNameValues(E.Request.Headers, name, true)
+			redactNameValues(E.Response.Headers, name, true)
+			if strings.EqualFold(name, "Cookie") {
+				for i := range E.Request.Cookies {
+					E.Request.Cookies[i].Value = HarRedacted
+				}
+			}
+			if strings.EqualFold(name, "Set-Cookie") {
+				for i := range E.Response.Cookies {
+					E.Response.Cookies[i].Value = HarRedacted
+				}
+			}
+		}
+	}
+}
+
+// RedactParams replaces the values of the named url Params and form data,
+// in the request URL, query string and post data
+func RedactParams(names ...string) HarRedactor {
+	return func(E *HarEntry) {
+		for _, name := range names {
+			redactNameValues(E.Request.QueryString, name, false)
+			if E.Request.PostData != nil {
+				redactNameValues(E.Request.PostData.Params, name, false)
+			}
+		}
+		if u, err := url.Parse(E.Request.Url); err == nil && u.RawQuery != "" {
+			E.Request.Url = redactQuery(u, names)
+		}
+		if post := E.Request.PostData; post != nil && len(post.Params) > 0 {
+			if values, err := url.ParseQuery(post.Text); err == nil {
+				for _, name := range names {
+					if _, ok := values[name]; ok {
+						values[name] = []string{HarRedacted}
+					}
+				}
+				post.Text = values.Encode()
+			}
+		}
+	}
+}
+
+// RedactJson replaces the values of the named object fields, at any depth,
+// in the JSON request and response bodies
+func RedactJson(fields ...string) HarRedactor {
+	redact := func(text string) string {
+		var data interface{}
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.UseNumber()
+		if err := dec.Decode(&data); err != nil {
+			return text
+		}
+		if !redactJsonFields(data, fields) {
+			return text
+		}
+		redacted, err := json.Marshal(data)
+		if err != nil {
+			return text
+		}
+		return string(redacted)
+	}
+	return func(E *HarEntry) {
+		if E.Request.PostData != nil {
+			E.Request.PostData.Text = redact(E.Request.PostData.Text)
+		}
+		if E.Response.Content.Encoding == "" {
+			E.Response.Content.Text = redact(E.Response.Content.Text)
+		}
+	}
+}
+
+func redactNameValues(values []HarNameValue, name string, fold bool) {
+	for i := range values {
+		if values[i].Name == name || fold && strings.EqualFold(values[i].Name, name) {
+			values[i].Value = HarRedacted
+		}
+	}
+}
+
+func redactQuery(u *url.URL, names []string) string {
+	query := u.Query()
+	for _, name := range names {
+		if _, ok := query[name]; ok {
+			query[name] = []string{HarRedacted}
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// redactJsonFields replaces the named fields in data,
+// and reports whether any were found
+func redactJsonFields(data interface{}, fields []string) bool {
+	found := false
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			redacted := false
+			for _, field := range fields {
+				if key == field {
+					value[key] = HarRedacted
+					redacted = true
+					found = true
+				}
+			}
+			if !redacted && redactJsonFields(child, fields) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, child := range value {
+			if redactJsonFields(child, fields) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// Set the Har which every coming request is added to
+//
+// Frisby objects created after this call use it in Send()
+func (G *Suite) SetHar(har *Har) *Suite {
+	G.Har = har
+	return G
+}
+
+// Set the Har which the coming request is added to
+//
+// Set it to nil to send the request without the Global archive
+func (F *Frisby) SetHar(har *Har) *Frisby {
+	F.Har = har
+	return F
+}
@@ -0,0 +1,191 @@
+package frisby_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestHar(t *testing.T) {
+	ts := newEchoServer()
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "frisby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "traffic.har")
+
+	har := frisby.NewHar(filename, frisby.RedactHeaders("Authorization", "Set-Cookie")).
+		Redact(frisby.RedactParams("api_key"), frisby.RedactJson("password", "token"))
+
+	G := frisby.NewSuite("Test Har")
+	G.PrintProgressDot = false
+	G.SetHar(har)
+
+	G.CreateT(t, "Test Har login").
+		Post(ts.URL+"/login").
+		BasicAuth("user", "pass").
+		SetParam("api_key", "secret-key").
+		SetParam("page", "1").
+		SetCookie("theme", "dark").
+		SetJson(map[string]string{"user": "alice", "password": "hunter2"}).
+		Send().
+		ExpectStatus(200)
+
+	G.CreateT(t, "Test Har form").
+		Post(ts.URL+"/form").
+		SetData("api_key", "secret-key").
+		SetData("name", "alice").
+		Send().
+		ExpectStatus(200)
+
+	G.Create("Test Har without archive").SetHar(nil).Get(ts.URL).Send()
+
+	entries := har.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, but got %d", len(entries))
+	}
+
+	login := entries[0]
+	if login.Comment != "Test Har login" || login.Request.Method != "POST" || login.Response.Status != 200 {
+		t.Errorf("Unexpected login entry %+v", login)
+	}
+	if strings.Contains(login.Request.Url, "secret-key") || !strings.Contains(login.Request.Url, "page=1") {
+		t.Errorf("Expected api_key to be redacted from the url, but got %q", login.Request.Url)
+	}
+	for _, header := range login.Request.Headers {
+		if header.Name == "Authorization" && header.Value != frisby.HarRedacted {
+			t.Errorf("Expected the Authorization header to be redacted, but got %q", header.Value)
+		}
+	}
+	for _, param := range login.Request.QueryString {
+		if param.Name == "api_key" && param.Value != frisby.HarRedacted {
+			t.Errorf("Expected the api_key param to be redacted, but got %q", param.Value)
+		}
+	}
+	if len(login.Request.Cookies) != 1 || login.Request.Cookies[0].Value != "dark" {
+		t.Errorf("Expected the theme cookie, but got %+v", login.Request.Cookies)
+	}
+	if login.Request.PostData == nil || strings.Contains(login.Request.PostData.Text, "hunter2") ||
+		!strings.Contains(login.Request.PostData.Text, "alice") {
+		t.Errorf("Expected the password to be redacted from the post data, but got %+v", login.Request.PostData)
+	}
+	if strings.Contains(login.Response.Content.Text, "secret\"") || !strings.Contains(login.Response.Content.Text, "/login") {
+		t.Errorf("Expected the token to be redacted from the response, but got %q", login.Response.Content.Text)
+	}
+	if len(login.Response.Cookies) != 1 || login.Response.Cookies[0].Value != frisby.HarRedacted {
+		t.Errorf("Expected the session cookie to be redacted, but got %+v", login.Response.Cookies)
+	}
+	if login.Time <= 0 || login.Timings.Wait < 0 {
+		t.Errorf("Expected the entry to be timed, but got %v and %+v", login.Time, login.Timings)
+	}
+
+	form := entries[1].Request.PostData
+	if form == nil || strings.Contains(form.Text, "secret-key") || len(form.Params) != 2 ||
+		form.Params[0] != (frisby.HarNameValue{Name: "api_key", Value: frisby.HarRedacted}) {
+		t.Errorf("Expected the api_key form data to be redacted, but got %+v", form)
+	}
+
+	if err := har.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		Log struct {
+			Version string
+			Entries []map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Log.Version != "1.2" || len(saved.Log.Entries) != 2 {
+		t.Errorf("Expected a HAR 1.2 file with 2 entries, but got version %q with %d", saved.Log.Version, len(saved.Log.Entries))
+	}
+	for _, key := range []string{"startedDateTime", "time", "request", "response", "cache", "timings"} {
+		if _, ok := saved.Log.Entries[0][key]; !ok {
+			t.Errorf("Expected the saved entry to have %q", key)
+		}
+	}
+}
+
+func TestHarSendError(t *testing.T) {
+	ts := newEchoServer()
+	url := ts.URL
+	ts.Close()
+
+	har := frisby.NewHar("")
+	frisby.Create("Test Har send error").SetHar(har).Get(url).Send()
+
+	entries := har.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, but got %d", len(entries))
+	}
+	if entries[0].Response.Status != 0 || !strings.HasPrefix(entries[0].Comment, "Test Har send error: ") {
+		t.Errorf("Expected the entry of a failed request, but got %+v", entries[0])
+	}
+}
+
+func TestHarRoundTrips(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, `{"path": "/new"}`)
+	}))
+	defer ts.Close()
+
+	har := frisby.NewHar("")
+	G := frisby.NewSuite("Test Har round trips")
+	G.PrintProgressDot = false
+	G.SetHar(har)
+
+	G.CreateT(t, "Test Har redirect").
+		Get(ts.URL+"/old").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("path", "/new")
+
+	G.CreateT(t, "Test Har binary").
+		Post(ts.URL+"/new").
+		SetHeader("Content-Type", "application/octet-stream").
+		SetBody([]byte{0x08, 0xff, 0x12, 0x00}).
+		Send().
+		ExpectStatus(200)
+
+	entries := har.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, but got %d", len(entries))
+	}
+	redirect, target := entries[0], entries[1]
+	if redirect.Response.Status != http.StatusFound || redirect.Response.RedirectURL != "/new" ||
+		!strings.HasSuffix(redirect.Request.Url, "/old") {
+		t.Errorf("Expected the redirect entry, but got %+v", redirect)
+	}
+	if target.Response.Status != 200 || !strings.HasSuffix(target.Request.Url, "/new") ||
+		target.Response.Content.Text != `{"path": "/new"}` {
+		t.Errorf("Expected the redirected entry, but got %+v", target)
+	}
+	if redirect.Comment != "Test Har redirect" || target.StartedDateTime.Before(redirect.StartedDateTime) {
+		t.Errorf("Expected the entries in order, but got %+v and %+v", redirect, target)
+	}
+
+	post := entries[2].Request.PostData
+	if post == nil || post.Text != "" || post.Comment != "binary body of 4 bytes omitted" || entries[2].Request.BodySize != 4 {
+		t.Errorf("Expected the binary body to be left out, but got %+v", post)
+	}
+}
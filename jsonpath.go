@@ -0,0 +1,664 @@
+package frisby
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FindJson returns the values in json matched by path
+//
+// path can be one of
+//
+//	a dot joined field names,     ex: 'path.to.array.7.field'
+//	a JSON Pointer (RFC 6901),    ex: '/path/to/array/7/field.with.dots'
+//	a JSONPath,                   ex: '$.path.to.array[?(@.price < 10)].name'
+//
+// Field names are joined with Global.PathSeparator, or the PathSeparator
+// of the Suite in expectations, and integer field names index into arrays.
+//
+// JSONPath supports names, ['quoted names'], indexes, negative indexes,
+// unions [0,2], slices [start:end:step], wildcards *, recursive descent ..
+// and filters [?(@.field op value)] with ==, !=, <, <=, >, >=, &&, || and !.
+//
+// A path which does not match returns no values, while a JSON null matches nil.
+func FindJson(json interface{}, path string) ([]interface{}, error) {
+	return findJson(json, path, Global.PathSeparator)
+}
+
+// findJson is FindJson with field names joined by separator
+func findJson(json interface{}, path, separator string) ([]interface{}, error) {
+	switch {
+	case isJsonPath(path):
+		steps, err := parseJsonPath(path)
+		if err != nil {
+			return nil, err
+		}
+		return evalJsonPath(steps, json, json), nil
+	case strings.HasPrefix(path, "/"):
+		return findJsonPointer(json, path)
+	default:
+		return findJsonFields(json, path, separator), nil
+	}
+}
+
+func isJsonPath(path string) bool {
+	return path == "$" || strings.HasPrefix(path, "$.") || strings.HasPrefix(path, "$[")
+}
+
+// findJsonFields progresses down the dot joined field names,
+// accessing array indexes for integer field names
+func findJsonFields(json interface{}, path, separator string) []interface{} {
+	if path == "" {
+		return []interface{}{json}
+	}
+	for _, segment := range strings.Split(path, separator) {
+		switch val := json.(type) {
+		case []interface{}:
+			// If the path segment is an integer, and we're at an array, access the index.
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(val) {
+				return nil
+			}
+			json = val[index]
+		case map[string]interface{}:
+			child, ok := val[segment]
+			if !ok {
+				return nil
+			}
+			json = child
+		default:
+			return nil
+		}
+	}
+	return []interface{}{json}
+}
+
+func findJsonPointer(json interface{}, pointer string) ([]interface{}, error) {
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.Replace(token, "~1", "/", -1)
+		token = strings.Replace(token, "~0", "~", -1)
+
+		switch val := json.(type) {
+		case map[string]interface{}:
+			child, ok := val[token]
+			if !ok {
+				return nil, nil
+			}
+			json = child
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(val) || (len(token) > 1 && token[0] == '0') {
+				return nil, nil
+			}
+			json = val[index]
+		default:
+			return nil, nil
+		}
+	}
+	return []interface{}{json}, nil
+}
+
+// a step of a JSONPath, like .name, [0,1] or ..*
+type jsonPathStep struct {
+	recursive bool
+	selectors []jsonPathSelector
+}
+
+// a single selector within a step
+type jsonPathSelector struct {
+	name     *string
+	index    *int
+	wildcard bool
+	slice    *[3]*int
+	filter   jsonPathExpr
+}
+
+// jsonPathParser is a recursive descent parser for JSONPath and filter expressions
+type jsonPathParser struct {
+	path string
+	pos  int
+}
+
+func parseJsonPath(path string) ([]jsonPathStep, error) {
+	p := &jsonPathParser{path: path}
+	if !p.consume("$") {
+		return nil, p.errorf("expected '$'")
+	}
+	steps, err := p.steps()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.path[p.pos:])
+	}
+	return steps, nil
+}
+
+func (p *jsonPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid JSONPath %q at %d: %s", p.path, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *jsonPathParser) done() bool {
+	return p.pos >= len(p.path)
+}
+
+func (p *jsonPathParser) peek(s string) bool {
+	return strings.HasPrefix(p.path[p.pos:], s)
+}
+
+func (p *jsonPathParser) consume(s string) bool {
+	if p.peek(s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *jsonPathParser) skipSpace() {
+	for !p.done() && p.path[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// steps parses .name, .*, ..name, ..*, [...] and ..[...] until none is left
+func (p *jsonPathParser) steps() ([]jsonPathStep, error) {
+	steps := make([]jsonPathStep, 0)
+	for !p.done() {
+		step := jsonPathStep{}
+		switch {
+		case p.consume(".."):
+			step.recursive = true
+			if p.peek("[") {
+				break
+			}
+			fallthrough
+		case p.consume("."):
+			if p.consume("*") {
+				step.selectors = []jsonPathSelector{{wildcard: true}}
+			} else {
+				name := p.name()
+				if name == "" {
+					return nil, p.errorf("expected a field name")
+				}
+				step.selectors = []jsonPathSelector{{name: &name}}
+			}
+		case p.peek("["):
+		default:
+			return steps, nil
+		}
+
+		if step.selectors == nil {
+			selectors, err := p.brackets()
+			if err != nil {
+				return nil, err
+			}
+			step.selectors = selectors
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (p *jsonPathParser) name() string {
+	start := p.pos
+	for !p.done() && strings.IndexByte(".[]()!=<>&|, ", p.path[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.path[start:p.pos]
+}
+
+// brackets parses [selector, selector, ...]
+func (p *jsonPathParser) brackets() ([]jsonPathSelector, error) {
+	if !p.consume("[") {
+		return nil, p.errorf("expected '['")
+	}
+	selectors := make([]jsonPathSelector, 0)
+	for {
+		p.skipSpace()
+		selector, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+		p.skipSpace()
+		if p.consume("]") {
+			return selectors, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *jsonPathParser) selector() (jsonPathSelector, error) {
+	switch {
+	case p.consume("*"):
+		return jsonPathSelector{wildcard: true}, nil
+	case p.consume("?"):
+		expr, err := p.or()
+		if err != nil {
+			return jsonPathSelector{}, err
+		}
+		return jsonPathSelector{filter: expr}, nil
+	case p.peek("'") || p.peek(`"`):
+		name, err := p.quoted()
+		if err != nil {
+			return jsonPathSelector{}, err
+		}
+		return jsonPathSelector{name: &name}, nil
+	}
+
+	// index or slice
+	var parts [3]*int
+	for i := 0; i < 3; i++ {
+		p.skipSpace()
+		if n, ok := p.integer(); ok {
+			parts[i] = &n
+		}
+		p.skipSpace()
+		if i == 2 || !p.consume(":") {
+			if i == 0 {
+				if parts[0] == nil {
+					return jsonPathSelector{}, p.errorf("expected a selector")
+				}
+				return jsonPathSelector{index: parts[0]}, nil
+			}
+			break
+		}
+	}
+	return jsonPathSelector{slice: &parts}, nil
+}
+
+func (p *jsonPathParser) integer() (int, bool) {
+	start := p.pos
+	p.consume("-")
+	for !p.done() && p.path[p.pos] >= '0' && p.path[p.pos] <= '9' {
+		p.pos++
+	}
+	n, err := strconv.Atoi(p.path[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false
+	}
+	return n, true
+}
+
+func (p *jsonPathParser) quoted() (string, error) {
+	quote := p.path[p.pos]
+	p.pos++
+	var buf strings.Builder
+	for !p.done() {
+		c := p.path[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && !p.done():
+			buf.WriteByte(p.path[p.pos])
+			p.pos++
+		case c == quote:
+			return buf.String(), nil
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// jsonPathExpr is a parsed filter expression, evaluated
+// with the root document and the current node for @
+type jsonPathExpr func(root, node interface{}) interface{}
+
+func (p *jsonPathParser) or() (jsonPathExpr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.consume("||"); p.skipSpace() {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(root, node interface{}) interface{} {
+			return truthy(l(root, node)) || truthy(right(root, node))
+		}
+	}
+	return left, nil
+}
+
+func (p *jsonPathParser) and() (jsonPathExpr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); p.consume("&&"); p.skipSpace() {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(root, node interface{}) interface{} {
+			return truthy(l(root, node)) && truthy(right(root, node))
+		}
+	}
+	return left, nil
+}
+
+func (p *jsonPathParser) unary() (jsonPathExpr, error) {
+	p.skipSpace()
+	if p.peek("!") && !p.peek("!=") {
+		p.pos++
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(root, node interface{}) interface{} {
+			return !truthy(expr(root, node))
+		}, nil
+	}
+	if p.consume("(") {
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return expr, nil
+	}
+	return p.comparison()
+}
+
+func (p *jsonPathParser) comparison() (jsonPathExpr, error) {
+	left, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			p.skipSpace()
+			right, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			op := op
+			return func(root, node interface{}) interface{} {
+				return compareJson(op, left(root, node), right(root, node))
+			}, nil
+		}
+	}
+	return left, nil
+}
+
+// a missing value within a filter, which only compares equal to itself
+type jsonNothing struct{}
+
+func (p *jsonPathParser) value() (jsonPathExpr, error) {
+	p.skipSpace()
+	switch {
+	case p.peek("@") || p.peek("$"):
+		relative := p.path[p.pos] == '@'
+		p.pos++
+		steps, err := p.steps()
+		if err != nil {
+			return nil, err
+		}
+		return func(root, node interface{}) interface{} {
+			start := root
+			if relative {
+				start = node
+			}
+			matches := evalJsonPath(steps, root, start)
+			if len(matches) == 0 {
+				return jsonNothing{}
+			}
+			return matches[0]
+		}, nil
+	case p.peek("'") || p.peek(`"`):
+		str, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return func(root, node interface{}) interface{} { return str }, nil
+	case p.consume("true"):
+		return func(root, node interface{}) interface{} { return true }, nil
+	case p.consume("false"):
+		return func(root, node interface{}) interface{} { return false }, nil
+	case p.consume("null"):
+		return func(root, node interface{}) interface{} { return nil }, nil
+	}
+
+	start := p.pos
+	for !p.done() && strings.IndexByte("-+.eE0123456789", p.path[p.pos]) >= 0 {
+		p.pos++
+	}
+	num, err := strconv.ParseFloat(p.path[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("expected a value")
+	}
+	return func(root, node interface{}) interface{} { return num }, nil
+}
+
+func truthy(value interface{}) bool {
+	switch val := value.(type) {
+	case jsonNothing:
+		return false
+	case bool:
+		return val
+	}
+	return true
+}
+
+// compareJson compares numbers, strings, booleans and nulls
+func compareJson(op string, left, right interface{}) bool {
+	if _, ok := left.(jsonNothing); ok {
+		return op == "!=" && right != left
+	}
+	if _, ok := right.(jsonNothing); ok {
+		return op == "!="
+	}
+
+	if l, ok := jsonFloat(left); ok {
+		if r, ok := jsonFloat(right); ok {
+			switch op {
+			case "==":
+				return l == r
+			case "!=":
+				return l != r
+			case "<":
+				return l < r
+			case "<=":
+				return l <= r
+			case ">":
+				return l > r
+			case ">=":
+				return l >= r
+			}
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			switch op {
+			case "==":
+				return l == r
+			case "!=":
+				return l != r
+			case "<":
+				return l < r
+			case "<=":
+				return l <= r
+			case ">":
+				return l > r
+			case ">=":
+				return l >= r
+			}
+		}
+	}
+
+	switch op {
+	case "==":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	}
+	return false
+}
+
+// jsonFloat converts the numbers found in JSON and in Go to a float64
+func jsonFloat(value interface{}) (float64, bool) {
+	switch val := value.(type) {
+	case json.Number:
+		f, err := val.Float64()
+		return f, err == nil
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	}
+	return 0, false
+}
+
+func evalJsonPath(steps []jsonPathStep, root, json interface{}) []interface{} {
+	nodes := []interface{}{json}
+	for _, step := range steps {
+		next := make([]interface{}, 0)
+		for _, node := range nodes {
+			candidates := []interface{}{node}
+			if step.recursive {
+				candidates = descendants(node, candidates)
+			}
+			for _, candidate := range candidates {
+				for _, selector := range step.selectors {
+					next = append(next, selector.apply(root, candidate)...)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descendants appends every value below json in document order,
+// visiting object fields by sorted name
+func descendants(json interface{}, nodes []interface{}) []interface{} {
+	for _, child := range children(json) {
+		nodes = append(nodes, child)
+		nodes = descendants(child, nodes)
+	}
+	return nodes
+}
+
+func children(json interface{}) []interface{} {
+	switch val := json.(type) {
+	case []interface{}:
+		return val
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, 0, len(val))
+		for _, key := range keys {
+			values = append(values, val[key])
+		}
+		return values
+	}
+	return nil
+}
+
+func (S jsonPathSelector) apply(root, json interface{}) []interface{} {
+	switch {
+	case S.wildcard:
+		return children(json)
+	case S.name != nil:
+		if obj, ok := json.(map[string]interface{}); ok {
+			if child, ok := obj[*S.name]; ok {
+				return []interface{}{child}
+			}
+		}
+	case S.index != nil:
+		if ary, ok := json.([]interface{}); ok {
+			index := *S.index
+			if index < 0 {
+				index += len(ary)
+			}
+			if index >= 0 && index < len(ary) {
+				return []interface{}{ary[index]}
+			}
+		}
+	case S.slice != nil:
+		if ary, ok := json.([]interface{}); ok {
+			return sliceJson(ary, S.slice)
+		}
+	case S.filter != nil:
+		matches := make([]interface{}, 0)
+		for _, child := range children(json) {
+			if truthy(S.filter(root, child)) {
+				matches = append(matches, child)
+			}
+		}
+		return matches
+	}
+	return nil
+}
+
+func sliceJson(ary []interface{}, parts *[3]*int) []interface{} {
+	length := len(ary)
+	step := 1
+	if parts[2] != nil {
+		step = *parts[2]
+	}
+	if step == 0 {
+		return nil
+	}
+
+	bound := func(n *int, def int) int {
+		if n == nil {
+			return def
+		}
+		i := *n
+		if i < 0 {
+			i += length
+		}
+		if i < -1 {
+			i = -1
+		}
+		if i > length {
+			i = length
+		}
+		return i
+	}
+
+	matches := make([]interface{}, 0)
+	if step > 0 {
+		start, end := bound(parts[0], 0), bound(parts[1], length)
+		if start < 0 {
+			start = 0
+		}
+		for i := start; i < end; i += step {
+			matches = append(matches, ary[i])
+		}
+	} else {
+		start, end := bound(parts[0], length-1), bound(parts[1], -1)
+		if start >= length {
+			start = length - 1
+		}
+		for i := start; i > end; i += step {
+			matches = append(matches, ary[i])
+		}
+	}
+	return matches
+}
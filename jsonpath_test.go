@@ -0,0 +1,148 @@
+package frisby_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+const testJsonDoc = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 19.95}
+	},
+	"a.b": {"c/d": {"e~f": 1}},
+	"empty": [],
+	"nothing": null
+}`
+
+func decodeTestJson(t *testing.T) interface{} {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader([]byte(testJsonDoc)))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestFindJson(t *testing.T) {
+	doc := decodeTestJson(t)
+
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		// dot joined field names
+		{"store.bicycle.color", []interface{}{"red"}},
+		{"store.book.2.author", []interface{}{"Herman Melville"}},
+		{"store.missing", nil},
+		{"store.book.9", nil},
+		{"store.bicycle.color.name", nil},
+		{"nothing", []interface{}{nil}},
+
+		// JSON Pointer
+		{"/store/book/0/author", []interface{}{"Nigel Rees"}},
+		{"/a.b/c~1d/e~0f", []interface{}{json.Number("1")}},
+		{"/store/missing", nil},
+		{"/store/book/01", nil},
+
+		// JSONPath
+		{"$.store.bicycle.color", []interface{}{"red"}},
+		{"$['a.b']['c/d']['e~f']", []interface{}{json.Number("1")}},
+		{"$.store.book[*].author", []interface{}{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{"$..author", []interface{}{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{"$.store.book[-1].title", []interface{}{"The Lord of the Rings"}},
+		{"$.store.book[0,2].price", []interface{}{json.Number("8.95"), json.Number("8.99")}},
+		{"$.store.book[1:3].author", []interface{}{"Evelyn Waugh", "Herman Melville"}},
+		{"$.store.book[::2].author", []interface{}{"Nigel Rees", "Herman Melville"}},
+		{"$.store.book[::-1].price", []interface{}{json.Number("22.99"), json.Number("8.99"), json.Number("12.99"), json.Number("8.95")}},
+		{"$.store.book[?(@.isbn)].title", []interface{}{"Moby Dick", "The Lord of the Rings"}},
+		{"$.store.book[?(@.price < 10)].title", []interface{}{"Sayings of the Century", "Moby Dick"}},
+		{"$.store.book[?(@.category == 'fiction' && @.price > 20)].author", []interface{}{"J. R. R. Tolkien"}},
+		{"$.store.book[?(!@.isbn || @.price >= 22.99)].price", []interface{}{json.Number("8.95"), json.Number("12.99"), json.Number("22.99")}},
+		{"$..book[?(@.price <= $.store.bicycle.price)].price", []interface{}{json.Number("8.95"), json.Number("12.99"), json.Number("8.99")}},
+		{"$..[?(@.color)].price", []interface{}{json.Number("19.95")}},
+		{"$.empty[*]", []interface{}{}},
+		{"$.store.missing", []interface{}{}},
+	}
+
+	for _, test := range tests {
+		matches, err := frisby.FindJson(doc, test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		if len(matches) == 0 && len(test.expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(matches, test.expected) {
+			t.Errorf("%s: expected %v, but got %v", test.path, test.expected, matches)
+		}
+	}
+}
+
+func TestFindJsonErrors(t *testing.T) {
+	for _, path := range []string{"$.", "$[", "$['a'", "$[?(@.a ==)]", "$.a]", "$[1,]"} {
+		if _, err := frisby.FindJson(nil, path); err == nil {
+			t.Errorf("Expected an error for %q", path)
+		}
+	}
+}
+
+// named types for expected values
+type testPrice float64
+type testCount int32
+
+func TestExpectJsonPaths(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testJsonDoc)
+	}))
+	defer ts.Close()
+
+	frisby.CreateT(t, "Test ExpectJson paths").
+		Get(ts.URL).
+		Send().
+		ExpectJson("store.book.0.price", 8.95).
+		ExpectJson("/store/book/0/price", 8.95).
+		ExpectJson("store.book.0.price", testPrice(8.95)).
+		ExpectJson("store.book.0.price", float32(8.95)).
+		ExpectJson("/a.b/c~1d/e~0f", testCount(1)).
+		ExpectJson("/a.b/c~1d/e~0f", uint8(1)).
+		ExpectJson("$.store.book[?(@.isbn)].category", "fiction").
+		ExpectJsonAny("$..author", "Herman Melville").
+		ExpectJsonCount("$.store.book[?(@.price < 10)]", 2).
+		ExpectJsonCount("$.store.missing", 0).
+		ExpectJsonCount("store.missing", 0).
+		ExpectJsonCount("missing", 0).
+		ExpectJsonCount("nothing", 1).
+		ExpectJsonType("store.book.1", reflect.Map).
+		ExpectJsonType("$.store.book[*].title", reflect.String).
+		ExpectJsonLength("/store/book", 4).
+		ExpectJsonLength("$..book", 4)
+
+	F := frisby.Create("Test ExpectJson path failures").
+		Get(ts.URL).
+		Send().
+		ExpectJson("$.store.book[*].category", "fiction").
+		ExpectJsonAny("$..author", "Homer").
+		ExpectJsonCount("$..price", 1).
+		ExpectJson("/store/missing", nil).
+		ExpectJson("store.missing", nil).
+		ExpectJsonLength("$.store.book[*]", 4)
+
+	if len(F.Errs) != 6 {
+		t.Errorf("Expected 6 errors, but got %v", F.Errs)
+	}
+}
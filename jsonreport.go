@@ -0,0 +1,215 @@
+package frisby
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// JsonReport is a machine-readable report of every Frisby object
+type JsonReport struct {
+	Frisbies []FrisbyReport `json:"frisbies"`
+	Summary  JsonSummary    `json:"summary"`
+}
+
+// FrisbyReport is the report of a single Frisby object
+//
+// Requests is only set when Send() followed redirects or answered auth challenges.
+type FrisbyReport struct {
+	Name          string        `json:"name"`
+	Method        string        `json:"method"`
+	Url           string        `json:"url"`
+	Tags          []string      `json:"tags,omitempty"`
+	Status        int           `json:"status,omitempty"`
+	ExecutionTime float64       `json:"execution_time"`
+	Timing        *Timing       `json:"timing,omitempty"`
+	Requests      []SentRequest `json:"requests,omitempty"`
+	Attempts      int           `json:"attempts,omitempty"`
+	Passed        bool          `json:"passed"`
+	Asserts       []Assertion   `json:"asserts"`
+	Errors        []string      `json:"errors"`
+}
+
+// JsonSummary holds the totals of a run
+type JsonSummary struct {
+	Frisbies      int     `json:"frisbies"`
+	Failed        int     `json:"failed"`
+	NumRequest    int     `json:"requests"`
+	NumAsserts    int     `json:"asserts"`
+	NumErrored    int     `json:"errored"`
+	ExecutionTime float64 `json:"execution_time"`
+	// exceeded Suite budgets
+	Budgets []string `json:"budgets,omitempty"`
+	Passed  bool     `json:"passed"`
+}
+
+// lines written by StreamJsonLines() and WriteJsonSummary()
+type jsonRequestEvent struct {
+	Event         string  `json:"event"`
+	Name          string  `json:"name"`
+	Method        string  `json:"method"`
+	Url           string  `json:"url"`
+	Status        int     `json:"status,omitempty"`
+	ExecutionTime float64 `json:"execution_time"`
+	Timing        *Timing `json:"timing,omitempty"`
+	Error         string  `json:"error,omitempty"`
+}
+
+type jsonAssertEvent struct {
+	Event string `json:"event"`
+	Name  string `json:"name"`
+	Assertion
+}
+
+type jsonSummaryEvent struct {
+	Event string `json:"event"`
+	JsonSummary
+}
+
+// Creates the report for the Frisby Object
+func (F *Frisby) Report() FrisbyReport {
+	report := FrisbyReport{
+		Name:          F.Name,
+		Method:        F.Method,
+		Url:           F.Url,
+		Tags:          F.Tags,
+		ExecutionTime: F.ExecutionTime,
+		Attempts:      F.Attempts,
+		Passed:        len(F.Errs) == 0,
+		Asserts:       make([]Assertion, 0, len(F.Asserts)),
+		Errors:        make([]string, 0, len(F.Errs)),
+	}
+	if F.Resp != nil {
+		report.Status = F.Resp.StatusCode
+		report.Timing = &F.Timing
+	}
+	if len(F.Requests) > 1 {
+		report.Requests = F.Requests
+	}
+	report.Asserts = append(report.Asserts, F.Asserts...)
+	for _, e := range F.Errs {
+		report.Errors = append(report.Errors, e.Error())
+	}
+	return report
+}
+
+// Creates a JsonReport of every Frisby object
+//
+// The Frisby objects are only listed when the Suite keeps them, see KeepFrisbies.
+func (G *Suite) JsonReport() *JsonReport {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	report := &JsonReport{
+		Frisbies: make([]FrisbyReport, 0, len(G.Frisbies)),
+		Summary:  G.summary(),
+	}
+	for _, F := range G.Frisbies {
+		report.Frisbies = append(report.Frisbies, F.Report())
+	}
+	return report
+}
+
+// Creates the JsonSummary with the totals of every Frisby object
+func (G *Suite) Summary() JsonSummary {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	return G.summary()
+}
+
+func (G *Suite) summary() JsonSummary {
+	summary := JsonSummary{
+		Frisbies:      G.numFrisbies,
+		Failed:        G.numFailed,
+		NumRequest:    G.NumRequest,
+		NumAsserts:    G.NumAsserts,
+		NumErrored:    G.NumErrored,
+		ExecutionTime: G.executionTime,
+	}
+	for _, err := range G.checkBudgets() {
+		summary.Budgets = append(summary.Budgets, err.Error())
+	}
+	summary.Passed = summary.Failed == 0 && len(G.Errs) == 0 && len(summary.Budgets) == 0
+	return summary
+}
+
+// Write the JsonReport of every Frisby object to w
+//
+// It fails without writing when the Suite did not keep them, see KeepFrisbies.
+func (G *Suite) WriteJsonReport(w io.Writer) error {
+	if err := G.checkKept(); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(G.JsonReport())
+}
+
+// Write the JsonReport of every Frisby object to the given file
+//
+// It fails without writing when the Suite did not keep them, see KeepFrisbies.
+func (G *Suite) WriteJsonReportFile(filename string) error {
+	if err := G.checkKept(); err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := G.WriteJsonReport(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Write the JsonSummary as a single line of JSON to w
+//
+// Call it last when streaming to the same writer with StreamJsonLines()
+func (G *Suite) WriteJsonSummary(w io.Writer) error {
+	return json.NewEncoder(w).Encode(jsonSummaryEvent{"summary", G.Summary()})
+}
+
+// Stream a line of JSON to w for every request sent and every assertion made
+//
+// Request lines have the "request" event with the name, method, url, status,
+// execution_time and any error of the request. Assertion lines have the
+// "assert" event with the Frisby name and the Assertion fields.
+// Set w to nil to stop streaming.
+func (G *Suite) StreamJsonLines(w io.Writer) *Suite {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	G.jsonLines = w
+	return G
+}
+
+func (G *Suite) streamRequest(F *Frisby, err error) {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	if G.jsonLines == nil {
+		return
+	}
+	event := jsonRequestEvent{
+		Event:         "request",
+		Name:          F.Name,
+		Method:        F.Method,
+		Url:           F.Url,
+		ExecutionTime: F.ExecutionTime,
+	}
+	if F.Resp != nil {
+		event.Status = F.Resp.StatusCode
+		event.Timing = &F.Timing
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	json.NewEncoder(G.jsonLines).Encode(event)
+}
+
+func (G *Suite) streamAssertion(F *Frisby, assertion Assertion) {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	if G.jsonLines == nil {
+		return
+	}
+	json.NewEncoder(G.jsonLines).Encode(jsonAssertEvent{"assert", F.Name, assertion})
+}
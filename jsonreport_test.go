@@ -0,0 +1,80 @@
+package frisby_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestJsonReport(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	G := frisby.NewSuite("Test JsonReport")
+	G.PrintProgressDot = false
+	G.KeepFrisbies = true
+
+	buf := new(bytes.Buffer)
+	G.StreamJsonLines(buf)
+
+	F := G.Create("Test JsonReport").
+		Get(ts.URL+"/report").
+		Send().
+		ExpectStatus(200).
+		ExpectJson("path", "/missing")
+
+	report := F.Report()
+	if report.Name != F.Name || report.Method != "GET" || report.Status != 200 || report.Passed {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if len(report.Asserts) != 2 || len(report.Errors) != 1 {
+		t.Fatalf("Unexpected asserts %+v", report.Asserts)
+	}
+	status, json_assert := report.Asserts[0], report.Asserts[1]
+	if status.Kind != "status" || !status.Passed || status.Expected != 200 || status.Actual != 200 {
+		t.Errorf("Unexpected status assertion %+v", status)
+	}
+	if json_assert.Kind != "json" || json_assert.Passed || json_assert.Path != "path" || json_assert.Actual != "/report" {
+		t.Errorf("Unexpected json assertion %+v", json_assert)
+	}
+
+	G.WriteJsonSummary(buf)
+
+	events := make([]map[string]interface{}, 0)
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		event := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("%v: %s", err, scanner.Text())
+		}
+		events = append(events, event)
+	}
+
+	expected := []string{"request", "assert", "assert", "summary"}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, but got %v", len(expected), events)
+	}
+	for i, event := range events {
+		if event["event"] != expected[i] {
+			t.Errorf("Expected event %d to be %q, but got %v", i, expected[i], event)
+		}
+	}
+	if events[0]["status"] != 200.0 || events[2]["passed"] != false || events[3]["passed"] != false {
+		t.Errorf("Unexpected events %v", events)
+	}
+
+	full := new(bytes.Buffer)
+	if err := G.WriteJsonReport(full); err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(frisby.JsonReport)
+	if err := json.Unmarshal(full.Bytes(), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Summary.Frisbies != 1 || len(decoded.Frisbies) != 1 || decoded.Summary.NumRequest != 1 {
+		t.Errorf("Unexpected summary %+v", decoded.Summary)
+	}
+}
@@ -0,0 +1,163 @@
+package frisby
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// base URL of JSON Schemas compiled from strings or Go values
+const jsonSchemaURL = "mem://frisby/schema.json"
+
+// JsonSchema is a compiled JSON Schema used by ExpectJsonSchema()
+//
+// Schemas without a "$schema" keyword are treated as draft 2020-12.
+type JsonSchema struct {
+	Location string
+
+	schema *jsonschema.Schema
+}
+
+// LoadJsonSchema compiles the JSON Schema in the given file
+//
+// Relative "$ref"s are resolved against the file location.
+func LoadJsonSchema(filename string) (*JsonSchema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	schema, err := compiler.Compile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &JsonSchema{Location: filename, schema: schema}, nil
+}
+
+// CompileJsonSchema compiles a JSON Schema
+//
+// schema can be a string or []byte holding the JSON text of the schema,
+// or any Go value which encodes to it, like a map[string]interface{}.
+func CompileJsonSchema(schema interface{}) (*JsonSchema, error) {
+	var data []byte
+	switch val := schema.(type) {
+	case string:
+		data = []byte(val)
+	case []byte:
+		data = val
+	default:
+		var err error
+		if data, err = json.Marshal(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	if err := compiler.AddResource(jsonSchemaURL, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	compiled, err := compiler.Compile(jsonSchemaURL)
+	if err != nil {
+		return nil, err
+	}
+	return &JsonSchema{Location: jsonSchemaURL, schema: compiled}, nil
+}
+
+// Validate v against the schema, returning the message
+// of every violation prefixed with its JSON pointer
+func (S *JsonSchema) Validate(v interface{}) []string {
+	err := S.schema.Validate(v)
+	if err == nil {
+		return nil
+	}
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	leaves := make([]*jsonschema.ValidationError, 0)
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			leaves = append(leaves, e)
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(verr)
+
+	// order by location, the causes follow map iteration order
+	sort.SliceStable(leaves, func(i, j int) bool {
+		return pointerLess(leaves[i].InstanceLocation, leaves[j].InstanceLocation)
+	})
+
+	violations := make([]string, 0, len(leaves))
+	for _, e := range leaves {
+		violations = append(violations, fmt.Sprintf("%q %s", e.InstanceLocation, e.Message))
+	}
+	return violations
+}
+
+// pointerLess orders JSON pointers token by token, array
+// indexes by number so "/items/2" comes before "/items/10"
+func pointerLess(a, b string) bool {
+	a_tokens, b_tokens := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(a_tokens) && i < len(b_tokens); i++ {
+		if a_tokens[i] == b_tokens[i] {
+			continue
+		}
+		a_index, a_err := strconv.Atoi(a_tokens[i])
+		b_index, b_err := strconv.Atoi(b_tokens[i])
+		if a_err == nil && b_err == nil {
+			return a_index < b_index
+		}
+		return a_tokens[i] < b_tokens[i]
+	}
+	return len(a_tokens) < len(b_tokens)
+}
+
+// ExpectJsonSchema validates the response JSON at path against a JSON Schema
+//
+// schema can be a *JsonSchema from LoadJsonSchema() or CompileJsonSchema(),
+// or anything CompileJsonSchema() accepts. Every violation is added
+// as a separate error with its JSON pointer, relative to path.
+//
+// path can be a dot joined field names, a JSON Pointer or a JSONPath,
+// see FindJson() for details. Every value matched by path is validated.
+// ex:  'path.to.subobject.field'
+func (F *Frisby) ExpectJsonSchema(path string, schema interface{}) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+
+	compiled, ok := schema.(*JsonSchema)
+	if !ok {
+		var err error
+		if compiled, err = CompileJsonSchema(schema); err != nil {
+			F.addAssertion("json_schema", path, nil, nil, err.Error())
+			return F
+		}
+	}
+
+	matches, err := F.responseJson(path)
+	if err != nil {
+		F.addAssertion("json_schema", path, compiled.Location, nil, err.Error())
+		return F
+	}
+
+	err_strs := make([]string, 0)
+	for _, match := range matches {
+		for _, violation := range compiled.Validate(match) {
+			err_strs = append(err_strs, fmt.Sprintf("Expect Json %q to match schema, but %s", path, violation))
+		}
+	}
+	F.addAssertion("json_schema", path, compiled.Location, nil, err_strs...)
+
+	return F
+}
@@ -0,0 +1,99 @@
+package frisby_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+const testSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["method", "path", "items"],
+	"properties": {
+		"method": {"enum": ["GET", "POST"]},
+		"path": {"type": "string", "pattern": "^/"},
+		"items": {"type": "array", "items": {"type": "integer"}}
+	}
+}`
+
+func TestExpectJsonSchema(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "frisby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "schema.json")
+	if err := ioutil.WriteFile(filename, []byte(testSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := frisby.LoadJsonSchema(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frisby.CreateT(t, "Test ExpectJsonSchema").
+		Get(ts.URL+"/schema").
+		Send().
+		ExpectJsonSchema("", testSchema).
+		ExpectJsonSchema("", schema).
+		ExpectJsonSchema("items", map[string]interface{}{
+			"type":     "array",
+			"minItems": 3,
+		})
+}
+
+func TestExpectJsonSchemaViolations(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	F := frisby.Create("Test ExpectJsonSchema violations").
+		Delete(ts.URL+"/schema").
+		Send().
+		ExpectJsonSchema("", `{
+			"type": "object",
+			"properties": {
+				"method": {"const": "GET"},
+				"items": {"type": "array", "items": {"maximum": 1}}
+			}
+		}`)
+
+	errs := F.Errors()
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, but got %v", errs)
+	}
+	for i, pointer := range []string{`"/items/1"`, `"/items/2"`, `"/method"`} {
+		if !strings.Contains(errs[i].Error(), pointer) {
+			t.Errorf("Expected error %d to contain %s, but got %q", i, pointer, errs[i])
+		}
+	}
+
+	assertion := F.Asserts[0]
+	if assertion.Kind != "json_schema" || assertion.Passed {
+		t.Errorf("Unexpected assertion %+v", assertion)
+	}
+}
+
+func TestJsonSchemaViolationOrder(t *testing.T) {
+	schema, err := frisby.CompileJsonSchema(`{"type": "array", "items": {"maximum": 1}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := make([]interface{}, 12)
+	for i := range items {
+		items[i] = 0
+	}
+	items[2], items[10] = 5, 5
+
+	violations := schema.Validate(items)
+	if len(violations) != 2 || !strings.HasPrefix(violations[0], `"/2"`) || !strings.HasPrefix(violations[1], `"/10"`) {
+		t.Errorf("Expected the violations of /2 and /10 in order, but got %q", violations)
+	}
+}
@@ -0,0 +1,175 @@
+package frisby
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// JUnitReport is a JUnit XML report, as read by CI systems like Jenkins or GitLab
+//
+// Each Frisby object becomes a <testcase> with a <failure> for every error,
+// grouped into <testsuite> blocks with AddSuite().
+type JUnitReport struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr,omitempty"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+
+	time float64
+}
+
+// JUnitTestSuite is a <testsuite> block of a JUnitReport
+type JUnitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []JUnitProperty `xml:"properties>property,omitempty"`
+	TestCases  []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitProperty is a <property> of a JUnitTestSuite
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// JUnitTestCase is the <testcase> of a single Frisby object
+type JUnitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	Classname string         `xml:"classname,attr"`
+	Time      string         `xml:"time,attr"`
+	Failures  []JUnitFailure `xml:"failure,omitempty"`
+}
+
+// JUnitFailure is a <failure> holding one of the Frisby errors
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// Creates a new, empty JUnitReport with the given name
+func NewJUnitReport(name string) *JUnitReport {
+	return &JUnitReport{
+		Name:   name,
+		Time:   junitTime(0),
+		Suites: make([]JUnitTestSuite, 0),
+	}
+}
+
+// Add a <testsuite> with a <testcase> for each of the given Frisby objects
+func (R *JUnitReport) AddSuite(name string, frisbies []*Frisby, properties ...JUnitProperty) *JUnitReport {
+	suite := JUnitTestSuite{
+		Name:       name,
+		Properties: properties,
+		TestCases:  make([]JUnitTestCase, 0, len(frisbies)),
+	}
+
+	suite_time := 0.0
+	for _, F := range frisbies {
+		test_case := JUnitTestCase{
+			Name:      F.Name,
+			Classname: name,
+			Time:      junitTime(F.ExecutionTime),
+		}
+		for _, e := range F.Errs {
+			test_case.Failures = append(test_case.Failures, JUnitFailure{
+				Message: e.Error(),
+				Type:    "frisby",
+				Content: e.Error(),
+			})
+		}
+
+		suite.Tests++
+		if len(F.Errs) > 0 {
+			suite.Failures++
+		}
+		suite_time += F.ExecutionTime
+		suite.TestCases = append(suite.TestCases, test_case)
+	}
+	suite.Time = junitTime(suite_time)
+
+	R.Tests += suite.Tests
+	R.Failures += suite.Failures
+	R.time += suite_time
+	R.Time = junitTime(R.time)
+	R.Suites = append(R.Suites, suite)
+
+	return R
+}
+
+// Write the JUnit XML report to w
+func (R *JUnitReport) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(R); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// Write the JUnit XML report to the given file
+func (R *JUnitReport) WriteFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := R.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func junitTime(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
+
+// Creates a JUnitReport with a single <testsuite> holding every Frisby object
+// kept by the Suite, see KeepFrisbies
+//
+// The request, assertion and error counts are added as suite properties.
+func (G *Suite) JUnitReport(name string) *JUnitReport {
+	return NewJUnitReport(name).AddSuite(name, G.frisbies(), G.JUnitProperties()...)
+}
+
+// Write a JUnit XML report for every Frisby object to w
+//
+// It fails without writing when the Suite did not keep them, see KeepFrisbies.
+func (G *Suite) WriteJUnitReport(w io.Writer) error {
+	if err := G.checkKept(); err != nil {
+		return err
+	}
+	return G.JUnitReport("frisby").Write(w)
+}
+
+// Write a JUnit XML report for every Frisby object to the given file
+//
+// It fails without writing when the Suite did not keep them, see KeepFrisbies.
+func (G *Suite) WriteJUnitReportFile(filename string) error {
+	if err := G.checkKept(); err != nil {
+		return err
+	}
+	return G.JUnitReport("frisby").WriteFile(filename)
+}
+
+// The request, assertion and error counts as JUnit suite properties
+func (G *Suite) JUnitProperties() []JUnitProperty {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	return []JUnitProperty{
+		{Name: "frisby.requests", Value: strconv.Itoa(G.NumRequest)},
+		{Name: "frisby.asserts", Value: strconv.Itoa(G.NumAsserts)},
+		{Name: "frisby.errored", Value: strconv.Itoa(G.NumErrored)},
+	}
+}
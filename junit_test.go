@@ -0,0 +1,90 @@
+package frisby_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestJUnitReport(t *testing.T) {
+	pass := frisby.Create("Test JUnit pass")
+	pass.ExecutionTime = 0.25
+
+	fail := frisby.Create("Test JUnit fail").
+		AddError("first error").
+		AddError("second error")
+	fail.ExecutionTime = 0.5
+
+	buf := new(bytes.Buffer)
+	err := frisby.NewJUnitReport("frisby").
+		AddSuite("users", []*frisby.Frisby{pass, fail}).
+		AddSuite("empty", nil, frisby.JUnitProperty{Name: "key", Value: "value"}).
+		Write(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := new(frisby.JUnitReport)
+	if err := xml.Unmarshal(buf.Bytes(), report); err != nil {
+		t.Fatalf("%v\n%s", err, buf)
+	}
+
+	if report.Tests != 2 || report.Failures != 1 || report.Time != "0.750" || len(report.Suites) != 2 {
+		t.Fatalf("Unexpected report totals\n%s", buf)
+	}
+
+	users := report.Suites[0]
+	if users.Name != "users" || users.Tests != 2 || users.Failures != 1 || len(users.TestCases) != 2 {
+		t.Fatalf("Unexpected suite\n%s", buf)
+	}
+	if len(users.TestCases[0].Failures) != 0 {
+		t.Errorf("Expected no failures for %q", users.TestCases[0].Name)
+	}
+	failures := users.TestCases[1].Failures
+	if len(failures) != 2 || failures[0].Message != "first error" || failures[1].Message != "second error" {
+		t.Errorf("Unexpected failures %+v", failures)
+	}
+
+	empty := report.Suites[1]
+	if len(empty.Properties) != 1 || empty.Properties[0].Value != "value" {
+		t.Errorf("Unexpected properties %+v", empty.Properties)
+	}
+}
+
+func TestSuiteJUnitReport(t *testing.T) {
+	G := frisby.NewSuite("Test Suite JUnit")
+	G.PrintProgressDot = false
+	G.KeepFrisbies = true
+	G.Create("Test Suite JUnit pass")
+	F := G.Create("Test Suite JUnit fail").AddError("suite error")
+
+	report := G.JUnitReport("suite")
+	if len(report.Suites) != 1 || len(report.Suites[0].Properties) != 3 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+
+	cases := report.Suites[0].TestCases
+	if len(cases) != 2 {
+		t.Fatalf("Expected 2 testcases, but got %+v", cases)
+	}
+	last := cases[1]
+	if last.Name != F.Name || len(last.Failures) != 1 {
+		t.Errorf("Expected the last testcase to be %q, but got %+v", F.Name, last)
+	}
+}
+
+func TestWriteReportWithoutKeepFrisbies(t *testing.T) {
+	G := frisby.NewSuite("Test reports without KeepFrisbies")
+	G.PrintProgressDot = false
+	G.Create("Test reports without KeepFrisbies")
+
+	buf := new(bytes.Buffer)
+	if err := G.WriteJUnitReport(buf); err == nil || buf.Len() != 0 {
+		t.Errorf("Expected the JUnit report to fail, but got %v and %q", err, buf.String())
+	}
+	if err := G.WriteJsonReport(buf); err == nil || buf.Len() != 0 {
+		t.Errorf("Expected the JSON report to fail, but got %v and %q", err, buf.String())
+	}
+}
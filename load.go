@@ -0,0 +1,392 @@
+package frisby
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// Load replays a Frisby chain many times to measure latency and throughput
+//
+// Each iteration hands the chain a new Frisby object. Iterations run in their
+// own Suite, which inherits the settings and variables of the parent Suite,
+// so they don't add to its Frisbies, counters or errors.
+type Load struct {
+	Name string
+
+	// the Suite whose settings and variables iterations inherit
+	Suite *Suite
+
+	// number of iterations, ignored when 0 and Duration is set
+	Requests int
+	// stop starting iterations after this long, when set
+	Duration time.Duration
+	// number of iterations running at once
+	Concurrency int
+	// maximum iterations started per second, 0 for as fast as possible
+	Rate float64
+
+	chain RunFunc
+}
+
+// LoadReport summarizes the iterations of a Load run
+//
+// Times are in seconds.
+type LoadReport struct {
+	Name          string         `json:"name"`
+	Iterations    int            `json:"iterations"`
+	Failed        int            `json:"failed"`
+	NumRequest    int            `json:"requests"`
+	NumAsserts    int            `json:"asserts"`
+	FailedAsserts int            `json:"failed_asserts"`
+	Duration      float64        `json:"duration"`
+	Throughput    float64        `json:"throughput"`
+	Latency       LoadLatency    `json:"latency"`
+	Histogram     []LoadBucket   `json:"histogram"`
+	Statuses      map[int]int    `json:"statuses"`
+	Errors        map[string]int `json:"errors,omitempty"`
+}
+
+// LoadLatency holds the ExecutionTime percentiles of the iterations
+type LoadLatency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// LoadBucket counts the iterations with an ExecutionTime up to Le,
+// and above the Le of the previous bucket
+//
+// The last bucket has no Le when it holds the times above every bound.
+type LoadBucket struct {
+	Le    float64 `json:"le,omitempty"`
+	Count int     `json:"count"`
+}
+
+// upper bounds of the histogram buckets, in seconds
+var loadBuckets = []float64{
+	0.001, 0.002, 0.005, 0.01, 0.02, 0.05, 0.1, 0.2, 0.5, 1, 2, 5, 10, 0,
+}
+
+// Creates a Load of chain in frisby.Global, running it once
+//
+// Set Requests or Duration, Concurrency and Rate before calling Run()
+func NewLoad(name string, chain RunFunc) *Load {
+	return Global.NewLoad(name, chain)
+}
+
+// Creates a Load of chain in the Suite, running it once
+func (G *Suite) NewLoad(name string, chain RunFunc) *Load {
+	return &Load{Name: name, Suite: G, Requests: 1, Concurrency: 1, chain: chain}
+}
+
+// Set the number of iterations
+func (L *Load) SetRequests(requests int) *Load {
+	L.Requests = requests
+	return L
+}
+
+// Set how long iterations are started for, instead of a number of iterations
+func (L *Load) SetDuration(duration time.Duration) *Load {
+	L.Requests = 0
+	L.Duration = duration
+	return L
+}
+
+// Set the number of iterations running at once
+func (L *Load) SetConcurrency(concurrency int) *Load {
+	L.Concurrency = concurrency
+	return L
+}
+
+// Set the maximum number of iterations started per second
+//
+// The rate is only reached when Concurrency allows for it.
+func (L *Load) SetRate(rate float64) *Load {
+	L.Rate = rate
+	return L
+}
+
+// the outcome of a single iteration
+type loadResult struct {
+	latency       float64
+	status        int
+	asserts       int
+	failedAsserts int
+	errs          []error
+}
+
+// Run the iterations and wait for them to finish
+func (L *Load) Run() *LoadReport {
+	suite := L.suite()
+
+	concurrency := L.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan struct{})
+	results := make(chan loadResult, concurrency)
+	start := time.Now()
+
+	go L.schedule(jobs)
+
+	done := make(chan struct{})
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			for range jobs {
+				F := suite.newFrisby(L.Name)
+				runChain(F, L.chain)
+				results <- newLoadResult(F)
+			}
+			done <- struct{}{}
+		}()
+	}
+	go func() {
+		for w := 0; w < concurrency; w++ {
+			<-done
+		}
+		close(results)
+	}()
+
+	report := &LoadReport{
+		Name:     L.Name,
+		Statuses: make(map[int]int),
+		Errors:   make(map[string]int),
+	}
+	latencies := make([]float64, 0, L.Requests)
+	for result := range results {
+		report.Iterations++
+		report.NumAsserts += result.asserts
+		report.FailedAsserts += result.failedAsserts
+		if len(result.errs) > 0 {
+			report.Failed++
+		}
+		for _, err := range result.errs {
+			report.Errors[err.Error()]++
+		}
+		if result.status != 0 {
+			report.Statuses[result.status]++
+		}
+		latencies = append(latencies, result.latency)
+	}
+
+	report.Duration = time.Since(start).Seconds()
+	report.NumRequest = suite.NumRequest
+	if report.Duration > 0 {
+		report.Throughput = float64(report.NumRequest) / report.Duration
+	}
+	report.Latency = loadLatency(latencies)
+	report.Histogram = loadHistogram(latencies)
+	return report
+}
+
+// schedule sends a job for every iteration to start, and closes jobs
+// when Requests were started or Duration is over
+func (L *Load) schedule(jobs chan<- struct{}) {
+	defer close(jobs)
+
+	var over <-chan time.Time
+	if L.Duration > 0 {
+		timer := time.NewTimer(L.Duration)
+		defer timer.Stop()
+		over = timer.C
+	}
+	var tick <-chan time.Time
+	if L.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / L.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for i := 0; L.Requests <= 0 || i < L.Requests; i++ {
+		if L.Requests <= 0 && over == nil {
+			return
+		}
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-over:
+				return
+			}
+		}
+		select {
+		case jobs <- struct{}{}:
+		case <-over:
+			return
+		}
+	}
+}
+
+// suite returns a Suite for the iterations, with the settings and
+// variables of the parent Suite but its own counters and errors
+func (L *Load) suite() *Suite {
+	G := L.Suite
+	suite := NewSuite(L.Name)
+	suite.PrintProgressDot = false
+
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	suite.Req = G.Req
+	suite.PathSeparator = G.PathSeparator
+	suite.SendOptions = G.SendOptions
+	for name, value := range G.Vars {
+		suite.Vars[name] = value
+	}
+	return suite
+}
+
+func newLoadResult(F *Frisby) loadResult {
+	result := loadResult{
+		latency: F.ExecutionTime,
+		asserts: len(F.Asserts),
+		errs:    F.Errs,
+	}
+	if F.Resp != nil {
+		result.status = F.Resp.StatusCode
+	}
+	for _, assertion := range F.Asserts {
+		if !assertion.Passed {
+			result.failedAsserts++
+		}
+	}
+	return result
+}
+
+func loadLatency(latencies []float64) LoadLatency {
+	if len(latencies) == 0 {
+		return LoadLatency{}
+	}
+	sort.Float64s(latencies)
+
+	sum := 0.0
+	for _, latency := range latencies {
+		sum += latency
+	}
+	return LoadLatency{
+		Min:  latencies[0],
+		Mean: sum / float64(len(latencies)),
+		P50:  percentile(latencies, 50),
+		P90:  percentile(latencies, 90),
+		P99:  percentile(latencies, 99),
+		Max:  latencies[len(latencies)-1],
+	}
+}
+
+// percentile returns the nearest rank percentile p of the sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	} else if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// loadHistogram counts the sorted latencies in loadBuckets,
+// leaving out the empty buckets above the largest latency
+func loadHistogram(latencies []float64) []LoadBucket {
+	buckets := make([]LoadBucket, 0, len(loadBuckets))
+	i := 0
+	for _, le := range loadBuckets {
+		if i == len(latencies) {
+			break
+		}
+		bucket := LoadBucket{Le: le}
+		for i < len(latencies) && (le == 0 || latencies[i] <= le) {
+			bucket.Count++
+			i++
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// The fraction of iterations with errors, between 0 and 1
+func (R *LoadReport) FailureRate() float64 {
+	if R.Iterations == 0 {
+		return 0
+	}
+	return float64(R.Failed) / float64(R.Iterations)
+}
+
+// The fraction of failed assertions, between 0 and 1
+func (R *LoadReport) AssertFailureRate() float64 {
+	if R.NumAsserts == 0 {
+		return 0
+	}
+	return float64(R.FailedAsserts) / float64(R.NumAsserts)
+}
+
+// Prints the summary of the Load run
+//
+// If there are any errors, they will be printed with their count
+func (R *LoadReport) PrintReport() *LoadReport {
+	fmt.Printf("\nLoad [%s]\n", R.Name)
+	fmt.Printf("  %d iterations, %d requests in %.2fs (%.1f req/s)\n",
+		R.Iterations, R.NumRequest, R.Duration, R.Throughput)
+	fmt.Printf("  failed %d/%d iterations (%.2f%%), %d/%d asserts (%.2f%%)\n",
+		R.Failed, R.Iterations, 100*R.FailureRate(), R.FailedAsserts, R.NumAsserts, 100*R.AssertFailureRate())
+	fmt.Printf("  latency min %s  mean %s  p50 %s  p90 %s  p99 %s  max %s\n",
+		loadTime(R.Latency.Min), loadTime(R.Latency.Mean), loadTime(R.Latency.P50),
+		loadTime(R.Latency.P90), loadTime(R.Latency.P99), loadTime(R.Latency.Max))
+
+	fmt.Printf("  histogram\n")
+	for _, bucket := range R.Histogram {
+		le := "+Inf"
+		if bucket.Le != 0 {
+			le = loadTime(bucket.Le)
+		}
+		fmt.Printf("    <= %-8s %d\n", le, bucket.Count)
+	}
+
+	fmt.Printf("  statuses\n")
+	statuses := make([]int, 0, len(R.Statuses))
+	for status := range R.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		fmt.Printf("    %d  %d\n", status, R.Statuses[status])
+	}
+
+	if len(R.Errors) > 0 {
+		fmt.Printf("  errors\n")
+		for _, err_str := range sortedKeys(R.Errors) {
+			fmt.Printf("    %d x %s\n", R.Errors[err_str], err_str)
+		}
+	}
+	return R
+}
+
+func loadTime(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Microsecond).String()
+}
+
+// Write the LoadReport as indented JSON to w
+func (R *LoadReport) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(R)
+}
+
+// Write the LoadReport as indented JSON to the given file
+func (R *LoadReport) WriteFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := R.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
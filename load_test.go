@@ -0,0 +1,96 @@
+package frisby_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestLoad(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// every 4th request fails
+		if atomic.AddInt32(&hits, 1)%4 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer ts.Close()
+
+	requests := frisby.Global.NumRequest
+
+	report := frisby.NewLoad("Test Load", func(F *frisby.Frisby) {
+		F.Get(ts.URL).
+			Send().
+			ExpectStatus(200).
+			ExpectJson("ok", true)
+	}).SetRequests(40).SetConcurrency(4).Run()
+
+	if report.Iterations != 40 || report.NumRequest != 40 {
+		t.Errorf("Expected 40 iterations and requests, but got %d and %d", report.Iterations, report.NumRequest)
+	}
+	if report.Failed != 10 || report.FailedAsserts != 10 || report.NumAsserts != 80 {
+		t.Errorf("Expected 10 failed iterations and 10/80 asserts, but got %d and %d/%d",
+			report.Failed, report.FailedAsserts, report.NumAsserts)
+	}
+	if report.Statuses[200] != 30 || report.Statuses[503] != 10 {
+		t.Errorf("Expected 30 200s and 10 503s, but got %v", report.Statuses)
+	}
+	if rate := report.AssertFailureRate(); rate != 0.125 {
+		t.Errorf("Expected an assert failure rate of 0.125, but got %v", rate)
+	}
+	latency := report.Latency
+	if !(latency.Min <= latency.P50 && latency.P50 <= latency.P90 &&
+		latency.P90 <= latency.P99 && latency.P99 <= latency.Max && latency.Max > 0) {
+		t.Errorf("Expected ordered latencies, but got %+v", latency)
+	}
+	counted := 0
+	for _, bucket := range report.Histogram {
+		counted += bucket.Count
+	}
+	if counted != 40 {
+		t.Errorf("Expected 40 latencies in the histogram, but got %d", counted)
+	}
+	if frisby.Global.NumRequest != requests {
+		t.Errorf("Expected no Global requests, but got %d", frisby.Global.NumRequest-requests)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := report.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded frisby.LoadReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Latency.P99 != latency.P99 || decoded.Statuses[503] != 10 {
+		t.Errorf("Expected the JSON report to round trip, but got %s", buf)
+	}
+}
+
+func TestLoadDuration(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	report := frisby.NewLoad("Test Load duration", func(F *frisby.Frisby) {
+		F.Get(ts.URL).Send()
+	}).SetDuration(200 * time.Millisecond).SetRate(50).SetConcurrency(2).Run()
+
+	// slow machines may send fewer, but the rate never allows more than
+	// the first iteration, one per 20ms tick and one racing the end
+	if report.NumRequest == 0 {
+		t.Errorf("Expected requests, but got none")
+	}
+	if report.Iterations > 12 {
+		t.Errorf("Expected at most 12 iterations at 50 per second, but got %d", report.Iterations)
+	}
+	if report.Failed != 0 {
+		t.Errorf("Expected no failures, but got %v", report.Errors)
+	}
+}
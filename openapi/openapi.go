@@ -0,0 +1,144 @@
+// Package openapi validates Frisby requests and responses against an OpenAPI 3 document
+//
+//	api, err := openapi.Load("openapi.yaml")
+//	frisby.Global.SetValidator(api)
+//
+// It is a separate package, so only tests which use it depend on kin-openapi.
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/verdverm/frisby"
+)
+
+// Validator is an OpenAPI 3 document which requests and responses are validated against
+//
+// Set it with Global.SetValidator() or Frisby.SetValidator() and every Send()
+// checks that the request and response conform to the matching operation.
+type Validator struct {
+	Doc *openapi3.T
+
+	router routers.Router
+}
+
+// Load loads and validates an OpenAPI 3 document in YAML or JSON from the given file
+func Load(filename string) (*Validator, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return newValidator(doc)
+}
+
+// Parse parses and validates an OpenAPI 3 document in YAML or JSON
+func Parse(data []byte) (*Validator, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	return newValidator(doc)
+}
+
+func newValidator(doc *openapi3.T) (*Validator, error) {
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	A := &Validator{Doc: doc}
+	return A, A.route()
+}
+
+func (A *Validator) route() error {
+	router, err := gorillamux.NewRouter(A.Doc)
+	if err != nil {
+		return err
+	}
+	A.router = router
+	return nil
+}
+
+// SetServers replaces the servers of the document, which requests are matched against
+//
+// This allows testing a local or staging deployment with the production document.
+// With no urls, operations are matched by path for any host.
+func (A *Validator) SetServers(urls ...string) error {
+	A.Doc.Servers = make(openapi3.Servers, 0, len(urls))
+	for _, url := range urls {
+		A.Doc.Servers = append(A.Doc.Servers, &openapi3.Server{URL: url})
+	}
+	return A.route()
+}
+
+// Validate checks the request as sent, with its body, and
+// the response of the Frisby object against the matching operation
+func (A *Validator) Validate(F *frisby.Frisby, req *http.Request, body []byte) {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	name := req.Method + " " + req.URL.Path
+
+	route, params, err := A.router.FindRoute(req)
+	if err != nil {
+		err_str := fmt.Sprintf("OpenAPI operation for %s not found: %v", name, err)
+		F.Assert("openapi_request", name, nil, nil, err_str)
+		return
+	}
+	operation := req.Method + " " + route.Path
+
+	options := &openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: params,
+		Route:      route,
+		Options:    options,
+	}
+	err = openapi3filter.ValidateRequest(context.Background(), input)
+	F.Assert("openapi_request", operation, nil, nil, errorStrings("request", operation, err)...)
+
+	content, err := F.Resp.Content()
+	if err != nil {
+		F.Assert("openapi_response", operation, nil, nil, err.Error())
+		return
+	}
+	resp_input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 F.Resp.StatusCode,
+		Header:                 F.Resp.Header,
+		Options:                options,
+	}
+	resp_input.SetBodyBytes(content)
+	err = openapi3filter.ValidateResponse(context.Background(), resp_input)
+	F.Assert("openapi_response", operation, nil, F.Resp.StatusCode, errorStrings("response", operation, err)...)
+}
+
+// errorStrings splits err into one message per mismatch
+func errorStrings(kind, operation string, err error) []string {
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	if multi, ok := err.(openapi3.MultiError); ok {
+		errs = multi
+	}
+
+	err_strs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		err_strs = append(err_strs, fmt.Sprintf("OpenAPI %s for %s is invalid: %v", kind, operation, e))
+	}
+	return err_strs
+}
@@ -0,0 +1,107 @@
+package openapi_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/verdverm/frisby"
+	"github.com/verdverm/frisby/openapi"
+)
+
+const testOpenAPI = `
+openapi: 3.0.3
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+        - name: fields
+          in: query
+          schema:
+            type: string
+            enum: [name, email]
+      responses:
+        "200":
+          description: the user
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [method, path, items]
+                properties:
+                  method:
+                    type: string
+                  items:
+                    type: array
+                    items:
+                      type: string
+`
+
+// newTestServer answers every request with its method and path
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"method": %q, "path": %q, "items": [1, 2, 3]}`, r.Method, r.URL.Path)
+	}))
+}
+
+func TestValidator(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	api, err := openapi.Parse([]byte(testOpenAPI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := api.SetServers(ts.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	F := frisby.Create("Test OpenAPI").
+		SetValidator(api).
+		Get(ts.URL+"/users/abc").
+		SetParam("fields", "phone").
+		Send()
+
+	errs := F.Errors()
+	expected := []string{
+		`OpenAPI request for GET /users/{id} is invalid: parameter "id" in path`,
+		`OpenAPI request for GET /users/{id} is invalid: parameter "fields" in query`,
+		`OpenAPI response for GET /users/{id} is invalid: response body doesn't match schema`,
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, but got %v", len(expected), errs)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(errs[i].Error(), prefix) {
+			t.Errorf("Expected error %d to start with %q, but got %q", i, prefix, errs[i])
+		}
+	}
+
+	kinds := []string{"openapi_request", "openapi_response"}
+	for i, assertion := range F.Asserts {
+		if assertion.Kind != kinds[i] || assertion.Path != "GET /users/{id}" || assertion.Passed {
+			t.Errorf("Unexpected assertion %+v", assertion)
+		}
+	}
+
+	F = frisby.Create("Test OpenAPI missing operation").
+		SetValidator(api).
+		Post(ts.URL + "/users/1").
+		Send()
+	if err := F.Error(); err == nil || !strings.Contains(err.Error(), "OpenAPI operation for POST /users/1 not found") {
+		t.Errorf("Expected a missing operation error, but got %v", err)
+	}
+}
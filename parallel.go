@@ -0,0 +1,91 @@
+package frisby
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Parallel runs Frisby chains concurrently with a bounded number of workers
+//
+// The Frisby objects are created in the order the chains were added,
+// so Run() results, Suite.PrintReport() and the JSON and JUnit reports
+// list them in that order no matter which chain finishes first.
+type Parallel struct {
+	// the Suite creating the Frisby objects
+	Suite *Suite
+
+	// maximum number of chains running at once,
+	// runtime.GOMAXPROCS(0) when less than 1
+	Workers int
+
+	names  []string
+	chains []RunFunc
+}
+
+// Creates a Parallel runner in frisby.Global with the given number of workers
+func NewParallel(workers int) *Parallel {
+	return Global.NewParallel(workers)
+}
+
+// Creates a Parallel runner in the Suite with the given number of workers
+func (G *Suite) NewParallel(workers int) *Parallel {
+	return &Parallel{Suite: G, Workers: workers}
+}
+
+// Add a chain, which is handed a new Frisby object with the given name
+//
+// Chains must not depend on each other, use Capture() and
+// variables only within a single chain.
+func (P *Parallel) Add(name string, chain RunFunc) *Parallel {
+	P.names = append(P.names, name)
+	P.chains = append(P.chains, chain)
+	return P
+}
+
+// Run every chain and wait for them, returning the Frisby objects in order
+//
+// A chain which panics gets the panic added as an error
+// instead of crashing the other chains.
+func (P *Parallel) Run() []*Frisby {
+	frisbies := make([]*Frisby, len(P.chains))
+	for i, name := range P.names {
+		frisbies[i] = P.Suite.Create(name)
+	}
+
+	workers := P.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				runChain(frisbies[i], P.chains[i])
+			}
+		}()
+	}
+	for i := range P.chains {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return frisbies
+}
+
+func runChain(F *Frisby, chain RunFunc) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(attemptAborted); ok {
+				return
+			}
+			F.AddError(fmt.Sprintf("panic: %v", r))
+		}
+	}()
+	chain(F)
+}
@@ -0,0 +1,90 @@
+package frisby_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestParallel(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	var running, most int32
+	P := frisby.NewParallel(3)
+	for i := 0; i < 10; i++ {
+		i := i
+		P.Add(fmt.Sprintf("Test Parallel %d", i), func(F *frisby.Frisby) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&most)
+				if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+
+			F.Get(ts.URL+fmt.Sprintf("/items/%d", i)).
+				Send().
+				ExpectStatus(200).
+				ExpectJson("path", fmt.Sprintf("/items/%d", i))
+			if i == 7 {
+				F.ExpectJson("path", "/other")
+			}
+			if i == 9 {
+				panic("chain 9")
+			}
+		})
+	}
+
+	frisbies := P.Run()
+
+	if most > 3 {
+		t.Errorf("Expected at most 3 chains at once, but got %d", most)
+	}
+	if len(frisbies) != 10 {
+		t.Fatalf("Expected 10 Frisby objects, but got %d", len(frisbies))
+	}
+	for i, F := range frisbies {
+		if name := fmt.Sprintf("Test Parallel %d", i); F.Name != name {
+			t.Errorf("Expected Frisby %d to be %q, but got %q", i, name, F.Name)
+		}
+		failed := i == 7 || i == 9
+		if failed != (len(F.Errs) > 0) {
+			t.Errorf("%s: unexpected errors %v", F.Name, F.Errs)
+		}
+	}
+}
+
+func TestParallelGlobal(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	requests := frisby.Global.NumRequest
+	asserts := frisby.Global.NumAsserts
+
+	// parallel subtests finish when their group does
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			i := i
+			frisby.Run(t, fmt.Sprintf("Test Parallel Global %d", i), func(F *frisby.Frisby) {
+				F.T.Parallel()
+				F.Get(ts.URL).
+					Send().
+					ExpectStatus(200).
+					Capture(fmt.Sprintf("method%d", i), "method")
+			})
+		}
+	})
+
+	if n := frisby.Global.NumRequest - requests; n != 20 {
+		t.Errorf("Expected 20 requests, but got %d", n)
+	}
+	if n := frisby.Global.NumAsserts - asserts; n != 20 {
+		t.Errorf("Expected 20 asserts, but got %d", n)
+	}
+}
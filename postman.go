@@ -0,0 +1,516 @@
+package frisby
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// postmanCollection is the part of a Postman v2.1 collection read by ImportPostman()
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Variable []postmanKeyValue `json:"variable"`
+}
+
+// postmanItem is a request or, with Item, a folder
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item"`
+	Request *postmanRequest `json:"request"`
+	Event   []postmanEvent  `json:"event"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Url    json.RawMessage   `json:"url"`
+	Header []postmanKeyValue `json:"header"`
+	Body   *postmanBody      `json:"body"`
+	Auth   *postmanAuth      `json:"auth"`
+}
+
+type postmanKeyValue struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Src      interface{} `json:"src"`
+	Type     string      `json:"type"`
+	Disabled bool        `json:"disabled"`
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw"`
+	Urlencoded []postmanKeyValue `json:"urlencoded"`
+	Formdata   []postmanKeyValue `json:"formdata"`
+	Options    struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+// postmanAuth holds the auth type and its attributes, like
+// {"type": "basic", "basic": [{"key": "username", "value": "..."}]}
+type postmanAuth map[string]json.RawMessage
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec json.RawMessage `json:"exec"`
+	} `json:"script"`
+}
+
+// LoadPostman reads a Postman v2.1 collection from the given file,
+// see ImportPostman()
+func LoadPostman(filename string) (*Spec, []string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	spec, warnings, err := ImportPostman(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return spec, warnings, nil
+}
+
+// ImportPostman converts a Postman v2.1 collection into a Spec
+//
+// Requests in folders are named "Folder / Request". Their method, URL,
+// headers, raw, urlencoded and form-data bodies, and basic or bearer
+// auth are converted. The collection variables become Vars, whose
+// {{name}} placeholders work the same way.
+//
+// Test scripts are matched statement by statement: pm.response.to.have.status(),
+// pm.response.to.have.header(), pm.expect(pm.response.text()).to.include(),
+// equality, type and length checks of pm.response.json() values, and
+// pm.environment.set() of those values or headers, which become captures.
+// Anything else is returned as a warning, as are unsupported bodies.
+func ImportPostman(data []byte) (*Spec, []string, error) {
+	collection := new(postmanCollection)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(collection); err != nil {
+		return nil, nil, err
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.1") {
+		return nil, nil, fmt.Errorf("unsupported Postman collection schema %q, expected v2.1", collection.Info.Schema)
+	}
+
+	im := &postmanImporter{spec: &Spec{Name: collection.Info.Name}}
+	for _, variable := range collection.Variable {
+		if variable.Disabled {
+			continue
+		}
+		if im.spec.Vars == nil {
+			im.spec.Vars = make(map[string]interface{})
+		}
+		im.spec.Vars[variable.Key] = variable.Value
+	}
+	if collection.Auth != nil {
+		global := new(SpecRequest)
+		im.auth(collection.Info.Name, collection.Auth, global)
+		if global.BasicAuth != nil || len(global.Headers) > 0 {
+			im.spec.Global = global
+		}
+	}
+	for _, item := range collection.Item {
+		im.item("", item)
+	}
+	return im.spec, im.warnings, nil
+}
+
+type postmanImporter struct {
+	spec     *Spec
+	warnings []string
+}
+
+func (im *postmanImporter) warn(name, format string, args ...interface{}) {
+	im.warnings = append(im.warnings, fmt.Sprintf("%s: %s", name, fmt.Sprintf(format, args...)))
+}
+
+func (im *postmanImporter) item(folder string, item postmanItem) {
+	name := item.Name
+	if folder != "" {
+		name = folder + " / " + item.Name
+	}
+	if item.Request == nil {
+		for _, child := range item.Item {
+			im.item(name, child)
+		}
+		return
+	}
+
+	req := item.Request
+	T := SpecTest{Name: name, Method: strings.ToUpper(req.Method)}
+	if T.Method == "" {
+		T.Method = "GET"
+	}
+	if !specMethod.MatchString(T.Method) {
+		im.warn(name, "skipped, unsupported method %s", T.Method)
+		return
+	}
+
+	var raw_url string
+	if err := json.Unmarshal(req.Url, &raw_url); err != nil {
+		var url_object struct {
+			Raw string `json:"raw"`
+		}
+		json.Unmarshal(req.Url, &url_object)
+		raw_url = url_object.Raw
+	}
+	if raw_url == "" {
+		im.warn(name, "skipped, missing url")
+		return
+	}
+	T.Url = raw_url
+
+	for _, header := range req.Header {
+		if header.Disabled {
+			continue
+		}
+		if T.Headers == nil {
+			T.Headers = make(map[string]string)
+		}
+		T.Headers[header.Key] = fmt.Sprint(header.Value)
+	}
+	if req.Auth != nil {
+		im.auth(name, req.Auth, &T.SpecRequest)
+	}
+	if req.Body != nil {
+		im.body(name, req.Body, &T)
+	}
+
+	for _, event := range item.Event {
+		if event.Listen == "test" {
+			im.tests(name, event.Script.Exec, &T)
+		}
+	}
+
+	im.spec.Tests = append(im.spec.Tests, T)
+}
+
+func (im *postmanImporter) auth(name string, auth *postmanAuth, R *SpecRequest) {
+	var auth_type string
+	json.Unmarshal((*auth)["type"], &auth_type)
+	attrs := make(map[string]string)
+	var values []postmanKeyValue
+	json.Unmarshal((*auth)[auth_type], &values)
+	for _, value := range values {
+		attrs[value.Key] = fmt.Sprint(value.Value)
+	}
+
+	switch auth_type {
+	case "noauth", "":
+	case "basic":
+		R.BasicAuth = &SpecBasicAuth{User: attrs["username"], Password: attrs["password"]}
+	case "bearer":
+		if R.Headers == nil {
+			R.Headers = make(map[string]string)
+		}
+		R.Headers["Authorization"] = "Bearer " + attrs["token"]
+	default:
+		im.warn(name, "unsupported auth type %s", auth_type)
+	}
+}
+
+func (im *postmanImporter) body(name string, body *postmanBody, T *SpecTest) {
+	set := func(m *map[string]string, key, value string) {
+		if *m == nil {
+			*m = make(map[string]string)
+		}
+		(*m)[key] = value
+	}
+
+	switch body.Mode {
+	case "", "none":
+	case "raw":
+		if strings.TrimSpace(body.Raw) == "" {
+			return
+		}
+		dec := json.NewDecoder(strings.NewReader(body.Raw))
+		dec.UseNumber()
+		if err := dec.Decode(&T.Json); err != nil || dec.More() {
+			T.Json = nil
+			T.Body = body.Raw
+			content_type, ok := postmanRawTypes[body.Options.Raw.Language]
+			for key := range T.Headers {
+				ok = ok && !strings.EqualFold(key, "Content-Type")
+			}
+			if ok {
+				set(&T.Headers, "Content-Type", content_type)
+			}
+		}
+	case "urlencoded":
+		for _, field := range body.Urlencoded {
+			if !field.Disabled {
+				set(&T.Data, field.Key, fmt.Sprint(field.Value))
+			}
+		}
+	case "formdata":
+		for _, field := range body.Formdata {
+			if field.Disabled {
+				continue
+			}
+			if field.Type != "file" {
+				set(&T.Data, field.Key, fmt.Sprint(field.Value))
+				continue
+			}
+			if src, ok := field.Src.(string); ok && src != "" {
+				set(&T.Files, field.Key, src)
+			} else {
+				im.warn(name, "unsupported form-data file %q, only a single src is converted", field.Key)
+			}
+		}
+	default:
+		im.warn(name, "unsupported body mode %s", body.Mode)
+	}
+}
+
+// the Content-Type Postman sends for the languages of raw bodies
+var postmanRawTypes = map[string]string{
+	"text":       "text/plain",
+	"javascript": "application/javascript",
+	"html":       "text/html",
+	"xml":        "application/xml",
+}
+
+// the parts of test scripts converted by ImportPostman()
+var (
+	postmanString  = `("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')`
+	postmanLiteral = `(-?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?|true|false|null|"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')`
+	postmanPath    = `(pm\.response\.json\(\)|[A-Za-z_$][\w$]*)((?:\.[A-Za-z_$][\w$]*|\[\d+\]|\[` + postmanString + `\])*)`
+	postmanEql     = `\.to\.(?:eql|equal|eq|deep\.equal|be\.equal|deep\.eq)\(`
+
+	postmanStatusRe = []*regexp.Regexp{
+		regexp.MustCompile(`pm\.response\.to\.have\.status\(\s*(\d+)\s*\)`),
+		regexp.MustCompile(`pm\.expect\(\s*pm\.response\.code\s*\)` + postmanEql + `\s*(\d+)\s*\)`),
+		regexp.MustCompile(`tests\[` + postmanString + `\]\s*=\s*responseCode\.code\s*===?\s*(\d+)`),
+	}
+	postmanHeaderRe  = regexp.MustCompile(`pm\.response\.to\.have\.header\(\s*` + postmanString + `\s*,\s*` + postmanString + `\s*\)`)
+	postmanContentRe = regexp.MustCompile(`pm\.expect\(\s*pm\.response\.text\(\)\s*\)\.to\.(?:include|contain|have\.string)\(\s*` + postmanString + `\s*\)`)
+	postmanJsonVarRe = regexp.MustCompile(`(?:var|let|const)\s+([A-Za-z_$][\w$]*)\s*=\s*pm\.response\.json\(\)`)
+	postmanEqlRe     = regexp.MustCompile(`pm\.expect\(\s*` + postmanPath + `\s*\)` + postmanEql + `\s*` + postmanLiteral + `\s*\)`)
+	postmanTypeRe    = regexp.MustCompile(`pm\.expect\(\s*` + postmanPath + `\s*\)\.to\.be\.(?:a|an)\(\s*` + postmanString + `\s*\)`)
+	postmanLengthRe  = regexp.MustCompile(`pm\.expect\(\s*` + postmanPath + `\s*\)\.to\.have\.(?:lengthOf|length)\(\s*(\d+)\s*\)`)
+	postmanSetRe     = regexp.MustCompile(`pm\.(?:environment|collectionVariables|globals|variables)\.set\(\s*` + postmanString + `\s*,\s*` + postmanPath + `\s*\)`)
+	postmanSetHdrRe  = regexp.MustCompile(`pm\.(?:environment|collectionVariables|globals|variables)\.set\(\s*` + postmanString + `\s*,\s*pm\.response\.headers\.get\(\s*` + postmanString + `\s*\)\s*\)`)
+
+	// statements which should have been converted
+	postmanCheckRe = regexp.MustCompile(`pm\.expect\(|pm\.response\.to\.|tests\[|pm\.(?:environment|collectionVariables|globals|variables)\.set\(`)
+
+	postmanIndexRe = regexp.MustCompile(`\[(\d+)\]|\[` + postmanString + `\]`)
+)
+
+// chai type names and the SpecExpect.JsonTypes they map to
+var postmanTypes = map[string]string{
+	"string":  "string",
+	"number":  "number",
+	"boolean": "boolean",
+	"object":  "object",
+	"array":   "array",
+	"null":    "null",
+}
+
+func (im *postmanImporter) tests(name string, exec json.RawMessage, T *SpecTest) {
+	var lines []string
+	if err := json.Unmarshal(exec, &lines); err != nil {
+		var script string
+		json.Unmarshal(exec, &script)
+		lines = []string{script}
+	}
+	script := strings.Join(lines, "\n")
+
+	// variables holding pm.response.json()
+	json_vars := map[string]bool{"pm.response.json()": true}
+	for _, match := range postmanJsonVarRe.FindAllStringSubmatch(script, -1) {
+		json_vars[match[1]] = true
+	}
+
+	expect := func() *SpecExpect {
+		if T.Expect == nil {
+			T.Expect = new(SpecExpect)
+		}
+		return T.Expect
+	}
+	set := func(m *map[string]string, key, value string) {
+		if *m == nil {
+			*m = make(map[string]string)
+		}
+		(*m)[key] = value
+	}
+
+	// spans of the script which were converted
+	converted := make([][]int, 0)
+	convert := func(re *regexp.Regexp, foo func(match []string) bool) {
+		for _, idx := range re.FindAllStringSubmatchIndex(script, -1) {
+			match := make([]string, len(idx)/2)
+			for i := range match {
+				if idx[2*i] >= 0 {
+					match[i] = script[idx[2*i]:idx[2*i+1]]
+				}
+			}
+			if foo(match) {
+				converted = append(converted, idx[:2])
+			}
+		}
+	}
+
+	// path converts a JavaScript property path into a json path
+	path := func(root, props string) (string, bool) {
+		if !json_vars[root] || props == "" {
+			return "", false
+		}
+		props = postmanIndexRe.ReplaceAllStringFunc(props, func(index string) string {
+			key := index[1 : len(index)-1]
+			if unquoted, ok := postmanUnquote(key); ok {
+				key = unquoted
+			}
+			return "." + key
+		})
+		return strings.TrimPrefix(props, "."), true
+	}
+
+	for _, re := range postmanStatusRe {
+		convert(re, func(match []string) bool {
+			status, _ := strconv.Atoi(match[len(match)-1])
+			expect().Status = status
+			return true
+		})
+	}
+	convert(postmanHeaderRe, func(match []string) bool {
+		key, _ := postmanUnquote(match[1])
+		value, _ := postmanUnquote(match[2])
+		set(&expect().Headers, key, value)
+		return true
+	})
+	convert(postmanContentRe, func(match []string) bool {
+		content, _ := postmanUnquote(match[1])
+		expect().Content = append(expect().Content, content)
+		return true
+	})
+	convert(postmanEqlRe, func(match []string) bool {
+		json_path, ok := path(match[1], match[2])
+		if !ok {
+			return false
+		}
+		value, ok := postmanValue(match[4])
+		if !ok {
+			return false
+		}
+		if expect().Json == nil {
+			expect().Json = make(map[string]interface{})
+		}
+		expect().Json[json_path] = value
+		return true
+	})
+	convert(postmanTypeRe, func(match []string) bool {
+		json_path, ok := path(match[1], match[2])
+		if !ok {
+			return false
+		}
+		type_name, _ := postmanUnquote(match[4])
+		spec_type, ok := postmanTypes[type_name]
+		if !ok {
+			return false
+		}
+		set(&expect().JsonTypes, json_path, spec_type)
+		return true
+	})
+	convert(postmanLengthRe, func(match []string) bool {
+		json_path, ok := path(match[1], match[2])
+		if !ok {
+			return false
+		}
+		length, _ := strconv.Atoi(match[4])
+		if expect().JsonLength == nil {
+			expect().JsonLength = make(map[string]int)
+		}
+		expect().JsonLength[json_path] = length
+		return true
+	})
+	convert(postmanSetRe, func(match []string) bool {
+		json_path, ok := path(match[2], match[3])
+		if !ok {
+			return false
+		}
+		var_name, _ := postmanUnquote(match[1])
+		set(&T.Capture, var_name, json_path)
+		return true
+	})
+	convert(postmanSetHdrRe, func(match []string) bool {
+		var_name, _ := postmanUnquote(match[1])
+		header, _ := postmanUnquote(match[2])
+		set(&T.CaptureHeaders, var_name, header)
+		return true
+	})
+
+	// warn about the checks which were not converted, once per line
+	sort.Slice(converted, func(i, j int) bool { return converted[i][0] < converted[j][0] })
+	warned := make(map[int]bool)
+	for _, idx := range postmanCheckRe.FindAllStringIndex(script, -1) {
+		done := false
+		for _, span := range converted {
+			if idx[0] >= span[0] && idx[0] < span[1] {
+				done = true
+				break
+			}
+		}
+		if done {
+			continue
+		}
+		start := strings.LastIndex(script[:idx[0]], "\n") + 1
+		if warned[start] {
+			continue
+		}
+		warned[start] = true
+		end := strings.Index(script[idx[0]:], "\n")
+		if end < 0 {
+			end = len(script)
+		} else {
+			end += idx[0]
+		}
+		im.warn(name, "skipped test %q", strings.TrimSpace(script[start:end]))
+	}
+}
+
+// postmanUnquote unquotes a single or double quoted JavaScript string
+func postmanUnquote(str string) (string, bool) {
+	if len(str) < 2 {
+		return str, false
+	}
+	if str[0] == '\'' && str[len(str)-1] == '\'' {
+		inner := strings.Replace(str[1:len(str)-1], `\'`, `'`, -1)
+		str = `"` + strings.Replace(inner, `"`, `\"`, -1) + `"`
+	}
+	unquoted, err := strconv.Unquote(str)
+	if err != nil {
+		return str, false
+	}
+	return unquoted, true
+}
+
+// postmanValue converts a JavaScript literal into the value of a SpecExpect.Json
+func postmanValue(literal string) (interface{}, bool) {
+	switch literal {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	if literal[0] == '"' || literal[0] == '\'' {
+		return postmanUnquote(literal)
+	}
+	return json.Number(literal), true
+}
@@ -0,0 +1,187 @@
+package frisby_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+const postmanCollection = `{
+  "info": {
+    "name": "Users API",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [{"key": "baseUrl", "value": "http://api.test"}],
+  "item": [
+    {
+      "name": "Auth",
+      "item": [
+        {
+          "name": "Login",
+          "request": {
+            "method": "POST",
+            "url": {"raw": "{{baseUrl}}/login", "host": ["{{baseUrl}}"], "path": ["login"]},
+            "header": [
+              {"key": "Accept", "value": "application/json"},
+              {"key": "X-Debug", "value": "1", "disabled": true}
+            ],
+            "body": {"mode": "raw", "raw": "{\"user\": \"alice\", \"remember\": true}", "options": {"raw": {"language": "json"}}}
+          },
+          "event": [{
+            "listen": "test",
+            "script": {"exec": [
+              "pm.test(\"Status is 200\", function () { pm.response.to.have.status(200); });",
+              "var jsonData = pm.response.json();",
+              "pm.expect(jsonData.data.auth.token).to.be.a('string');",
+              "pm.expect(jsonData.items[0]).to.eql(1);",
+              "pm.expect(jsonData[\"user\"].name).to.equal(\"alice\");",
+              "pm.expect(jsonData.items).to.have.lengthOf(3);",
+              "pm.environment.set(\"token\", jsonData.data.auth.token);",
+              "pm.collectionVariables.set('request', pm.response.headers.get('X-Request-Id'));",
+              "pm.expect(jsonData.items).to.include(2);"
+            ]}
+          }]
+        }
+      ]
+    },
+    {
+      "name": "Upload",
+      "request": {
+        "method": "PUT",
+        "url": "{{baseUrl}}/upload?dry=true",
+        "auth": {"type": "basic", "basic": [{"key": "username", "value": "user"}, {"key": "password", "value": "pass"}]},
+        "body": {"mode": "formdata", "formdata": [
+          {"key": "name", "value": "avatar", "type": "text"},
+          {"key": "file", "src": "/tmp/me.png", "type": "file"}
+        ]}
+      },
+      "event": [{
+        "listen": "test",
+        "script": {"exec": "pm.expect(pm.response.code).to.eql(201);\npm.response.to.have.header('Location', '/upload/1');\npm.expect(pm.response.text()).to.include('uploaded');"}
+      }]
+    },
+    {
+      "name": "Form",
+      "request": {
+        "method": "POST",
+        "url": "{{baseUrl}}/form",
+        "body": {"mode": "urlencoded", "urlencoded": [{"key": "a", "value": "1"}]}
+      }
+    },
+    {
+      "name": "Properties",
+      "request": {
+        "method": "PROPFIND",
+        "url": "{{baseUrl}}/dav/",
+        "body": {"mode": "raw", "raw": "<propfind><allprop/></propfind>", "options": {"raw": {"language": "xml"}}}
+      }
+    }
+  ]
+}`
+
+func TestImportPostman(t *testing.T) {
+	spec, warnings, err := frisby.ImportPostman([]byte(postmanCollection))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &frisby.Spec{
+		Name:   "Users API",
+		Global: &frisby.SpecRequest{Headers: map[string]string{"Authorization": "Bearer {{token}}"}},
+		Vars:   map[string]interface{}{"baseUrl": "http://api.test"},
+		Tests: []frisby.SpecTest{
+			{
+				Name: "Auth / Login", Method: "POST", Url: "{{baseUrl}}/login",
+				SpecRequest: frisby.SpecRequest{
+					Headers: map[string]string{"Accept": "application/json"},
+					Json:    map[string]interface{}{"user": "alice", "remember": true},
+				},
+				Expect: &frisby.SpecExpect{
+					Status:     200,
+					Json:       map[string]interface{}{"items.0": json.Number("1"), "user.name": "alice"},
+					JsonTypes:  map[string]string{"data.auth.token": "string"},
+					JsonLength: map[string]int{"items": 3},
+				},
+				Capture:        map[string]string{"token": "data.auth.token"},
+				CaptureHeaders: map[string]string{"request": "X-Request-Id"},
+			},
+			{
+				Name: "Upload", Method: "PUT", Url: "{{baseUrl}}/upload?dry=true",
+				SpecRequest: frisby.SpecRequest{
+					BasicAuth: &frisby.SpecBasicAuth{User: "user", Password: "pass"},
+					Data:      map[string]string{"name": "avatar"},
+					Files:     map[string]string{"file": "/tmp/me.png"},
+				},
+				Expect: &frisby.SpecExpect{
+					Status:  201,
+					Headers: map[string]string{"Location": "/upload/1"},
+					Content: []string{"uploaded"},
+				},
+			},
+			{
+				Name: "Form", Method: "POST", Url: "{{baseUrl}}/form",
+				SpecRequest: frisby.SpecRequest{Data: map[string]string{"a": "1"}},
+			},
+			{
+				Name: "Properties", Method: "PROPFIND", Url: "{{baseUrl}}/dav/",
+				Body:        "<propfind><allprop/></propfind>",
+				SpecRequest: frisby.SpecRequest{Headers: map[string]string{"Content-Type": "application/xml"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		got, _ := json.MarshalIndent(spec, "", "  ")
+		want, _ := json.MarshalIndent(expected, "", "  ")
+		t.Errorf("Expected spec\n%s\nbut got\n%s", want, got)
+	}
+
+	expected_warnings := []string{`Auth / Login: skipped test "pm.expect(jsonData.items).to.include(2);"`}
+	if !reflect.DeepEqual(warnings, expected_warnings) {
+		t.Errorf("Expected warnings %q, but got %q", expected_warnings, warnings)
+	}
+}
+
+func TestImportPostmanRun(t *testing.T) {
+	ts := newEchoServer()
+	defer ts.Close()
+
+	spec, _, err := frisby.ImportPostman([]byte(`{
+	  "info": {"name": "Echo", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	  "variable": [{"key": "baseUrl", "value": "` + ts.URL + `"}],
+	  "item": [{
+	    "name": "Echo",
+	    "request": {"method": "GET", "url": "{{baseUrl}}/echo?q=frisby"},
+	    "event": [{"listen": "test", "script": {"exec": [
+	      "pm.response.to.have.status(200);",
+	      "const body = pm.response.json();",
+	      "pm.expect(body.path).to.eql('/echo');",
+	      "pm.expect(body.query).to.eql('frisby');",
+	      "pm.globals.set('token', body.data.auth.token);"
+	    ]}}]
+	  }]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	G := frisby.NewSuite("Test ImportPostman")
+	G.PrintProgressDot = false
+	for _, F := range spec.RunSuite(G) {
+		if len(F.Errs) > 0 {
+			t.Errorf("Expected %q to pass, but got %v", F.Name, F.Errs)
+		}
+	}
+	if token, _ := G.GetVar("token"); token != "secret" {
+		t.Errorf("Expected the captured token to be %q, but got %v", "secret", token)
+	}
+}
+
+func TestImportPostmanSchema(t *testing.T) {
+	_, _, err := frisby.ImportPostman([]byte(`{"info": {"name": "Old", "schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}}`))
+	if err == nil {
+		t.Errorf("Expected a v1 collection to fail")
+	}
+}
@@ -0,0 +1,121 @@
+package frisby
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// attempt holds the results of a single run of an Eventually() or Retry()
+// chain, which are only reported for the last one
+type attempt struct {
+	asserts []Assertion
+	errs    []string
+	sendErr error
+}
+
+func (A *attempt) failed() bool {
+	return len(A.errs) > 0 || A.sendErr != nil
+}
+
+// attemptAborted is panicked by a failed Send() within an attempt, and
+// recovered by runChain(), to skip the expectations on the missing response
+// like T.Fatalf() does outside of attempts
+type attemptAborted struct{}
+
+// Eventually runs chain, which sends the request and checks the response,
+// every interval until all its expectations pass or timeout is over
+//
+// Only the errors and assertions of the last attempt are reported,
+// and F.Attempts holds the number of attempts.
+//
+//	F.Eventually(30*time.Second, time.Second, func(F *frisby.Frisby) {
+//		F.Send().ExpectJson("status", "done")
+//	})
+func (F *Frisby) Eventually(timeout, interval time.Duration, chain RunFunc) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	deadline := time.Now().Add(timeout)
+	return F.retry(chain, func(n int) (time.Duration, bool) {
+		return interval, time.Now().Add(interval).Before(deadline)
+	})
+}
+
+// Retry runs chain, which sends the request and checks the response,
+// up to n times until all its expectations pass
+//
+// It waits backoff after the first attempt and doubles the wait after each
+// following one. Only the errors and assertions of the last attempt are
+// reported, and F.Attempts holds the number of attempts.
+//
+// An attempt whose Send() fails stops there, and fails with the send error.
+// Files added by AddFile(), and other seekable file readers, are sent again
+// from their start on each attempt.
+func (F *Frisby) Retry(n int, backoff time.Duration, chain RunFunc) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	return F.retry(chain, func(attempts int) (time.Duration, bool) {
+		return retryBackoff(backoff, attempts), attempts < n
+	})
+}
+
+// retryBackoff doubles backoff for each attempt after the first,
+// and stops doubling before the wait would overflow
+func retryBackoff(backoff time.Duration, attempts int) time.Duration {
+	wait := backoff
+	for i := 1; i < attempts && wait <= math.MaxInt64/2; i++ {
+		wait *= 2
+	}
+	return wait
+}
+
+// retry runs chain until it passes, or next returns false for the number
+// of attempts so far, waiting the duration returned by next between attempts
+func (F *Frisby) retry(chain RunFunc, next func(attempts int) (time.Duration, bool)) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	var last *attempt
+	for F.Attempts = 1; ; F.Attempts++ {
+		last = &attempt{}
+		F.attempt = last
+		runChain(F, chain)
+		F.attempt = nil
+
+		if !last.failed() {
+			break
+		}
+		wait, ok := next(F.Attempts)
+		if !ok {
+			break
+		}
+		time.Sleep(wait)
+	}
+
+	// report the last attempt as if it ran on its own
+	for _, assertion := range last.asserts {
+		F.Suite.countAssert()
+		F.Asserts = append(F.Asserts, assertion)
+		F.Suite.streamAssertion(F, assertion)
+	}
+	for _, err_str := range last.errs {
+		F.AddError(err_str)
+	}
+	if last.failed() && F.Attempts > 1 {
+		F.AddError(fmt.Sprintf("Gave up after %d attempts", F.Attempts))
+	}
+	if last.sendErr != nil {
+		F.sendFailed(last.sendErr)
+	}
+	return F
+}
+
+// countAssert counts an assertion in the Suite, unless it is made
+// by an attempt which may not be reported
+func (F *Frisby) countAssert() {
+	if F.attempt == nil {
+		F.Suite.countAssert()
+	}
+}
@@ -0,0 +1,146 @@
+package frisby_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+// newJobServer reports a job as done from the given request on
+func newJobServer(done int32) *httptest.Server {
+	var hits int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "running"
+		if atomic.AddInt32(&hits, 1) >= done {
+			status = "done"
+		}
+		fmt.Fprintf(w, `{"status": %q}`, status)
+	}))
+}
+
+func TestEventually(t *testing.T) {
+	ts := newJobServer(3)
+	defer ts.Close()
+
+	asserts := frisby.Global.NumAsserts
+
+	F := frisby.CreateT(t, "Test Eventually").
+		Get(ts.URL).
+		Eventually(time.Second, 10*time.Millisecond, func(F *frisby.Frisby) {
+			F.Send().
+				ExpectStatus(200).
+				ExpectJson("status", "done")
+		})
+
+	if F.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, but got %d", F.Attempts)
+	}
+	if len(F.Asserts) != 2 || frisby.Global.NumAsserts-asserts != 2 {
+		t.Errorf("Expected the 2 assertions of the last attempt, but got %v", F.Asserts)
+	}
+	if report := F.Report(); report.Attempts != 3 || !report.Passed {
+		t.Errorf("Expected a passed report with 3 attempts, but got %+v", report)
+	}
+}
+
+func TestEventuallyTimeout(t *testing.T) {
+	ts := newJobServer(100)
+	defer ts.Close()
+
+	F := frisby.Create("Test Eventually timeout").
+		Get(ts.URL).
+		Eventually(100*time.Millisecond, 30*time.Millisecond, func(F *frisby.Frisby) {
+			F.Send().ExpectJson("status", "done")
+		})
+
+	if F.Attempts < 3 || F.Attempts > 4 {
+		t.Errorf("Expected 3 or 4 attempts, but got %d", F.Attempts)
+	}
+	// the failed expectation of the last attempt and the attempt count
+	if len(F.Errs) != 2 {
+		t.Errorf("Expected 2 errors, but got %v", F.Errs)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ts := newJobServer(3)
+	defer ts.Close()
+
+	start := time.Now()
+	F := frisby.Create("Test Retry").
+		Get(ts.URL).
+		Retry(2, 20*time.Millisecond, func(F *frisby.Frisby) {
+			F.Send().ExpectJson("status", "done")
+		})
+	if F.Attempts != 2 || len(F.Errs) != 2 {
+		t.Errorf("Expected 2 attempts with 2 errors, but got %d and %v", F.Attempts, F.Errs)
+	}
+
+	F = frisby.CreateT(t, "Test Retry passes").
+		Get(ts.URL).
+		Retry(5, 20*time.Millisecond, func(F *frisby.Frisby) {
+			F.Send().ExpectJson("status", "done")
+		})
+	if F.Attempts != 1 {
+		t.Errorf("Expected 1 attempt, but got %d", F.Attempts)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected to wait for the backoff, but took %v", elapsed)
+	}
+
+	F = frisby.Create("Test Retry send fails").
+		Get("http://127.0.0.1:1").
+		Retry(2, time.Millisecond, func(F *frisby.Frisby) {
+			F.Send().
+				ExpectStatus(200).
+				ExpectJson("status", "done")
+		})
+	if F.Attempts != 2 || len(F.Errs) != 2 || len(F.Asserts) != 0 {
+		t.Fatalf("Expected 2 attempts with 2 errors and no assertions, but got %d and %v", F.Attempts, F.Errs)
+	}
+	if !strings.HasPrefix(F.Errs[0].Error(), "Gave up after 2 attempts") || !strings.Contains(F.Errs[1].Error(), "connection refused") {
+		t.Errorf("Expected the send error of the last attempt, but got %v", F.Errs)
+	}
+}
+
+func TestRetryFiles(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := ioutil.ReadAll(file)
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		fmt.Fprintf(w, `{"content": %q}`, content)
+	}))
+	defer ts.Close()
+
+	filename := filepath.Join(t.TempDir(), "upload.txt")
+	if err := ioutil.WriteFile(filename, []byte("file content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	F := frisby.CreateT(t, "Test Retry files").
+		Post(ts.URL).
+		AddFile(filename).
+		Retry(3, time.Millisecond, func(F *frisby.Frisby) {
+			F.Send().
+				ExpectStatus(200).
+				ExpectJson("content", "file content")
+		})
+	if F.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, but got %d", F.Attempts)
+	}
+}
@@ -0,0 +1,214 @@
+package frisby
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Session is a cookie jar shared by Frisby objects, so the cookies set by
+// a response, like the one of a login, are sent with the coming requests
+//
+// Set it with Global.SetSession() or Frisby.SetSession().
+type Session struct {
+	mu  sync.Mutex
+	jar *cookiejar.Jar
+}
+
+// Creates a new empty Session
+func NewSession() *Session {
+	jar, _ := cookiejar.New(nil)
+	return &Session{jar: jar}
+}
+
+// SetCookies implements http.CookieJar
+func (S *Session) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	S.mu.Lock()
+	jar := S.jar
+	S.mu.Unlock()
+	jar.SetCookies(u, cookies)
+}
+
+// Cookies implements http.CookieJar
+func (S *Session) Cookies(u *url.URL) []*http.Cookie {
+	S.mu.Lock()
+	jar := S.jar
+	S.mu.Unlock()
+	return jar.Cookies(u)
+}
+
+// Get the value of the cookie the Session sends to rawurl
+func (S *Session) Cookie(rawurl, name string) (string, bool) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", false
+	}
+	for _, cookie := range S.Cookies(u) {
+		if cookie.Name == name {
+			return cookie.Value, true
+		}
+	}
+	return "", false
+}
+
+// Clear removes every cookie of the Session, as after a logout
+func (S *Session) Clear() *Session {
+	jar, _ := cookiejar.New(nil)
+	S.mu.Lock()
+	defer S.mu.Unlock()
+	S.jar = jar
+	return S
+}
+
+// Set the Session whose cookies are shared by the coming requests
+//
+// Frisby objects created after this call use it. Set it to nil
+// for each Frisby object to keep its own cookies.
+func (G *Suite) SetSession(session *Session) *Suite {
+	if session == nil {
+		// a nil *Session would be a non-nil http.CookieJar,
+		// and a nil jar gets Frisby objects a new jar each
+		G.Req.Client.Jar = nil
+	} else {
+		G.Req.Client.Jar = session
+	}
+	return G
+}
+
+// Set the Session whose cookies are sent with, and updated by,
+// the coming request
+//
+// Set it to nil to send the request with a new empty cookie jar,
+// without the Suite session
+func (F *Frisby) SetSession(session *Session) *Frisby {
+	if session == nil {
+		jar, _ := cookiejar.New(nil)
+		F.Req.Client.Jar = jar
+	} else {
+		F.Req.Client.Jar = session
+	}
+	return F
+}
+
+// responseCookie returns the cookie set by the response with the given name
+func (F *Frisby) responseCookie(name string) *http.Cookie {
+	for _, cookie := range F.Resp.Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// expectCookie checks an attribute of the response cookie with check,
+// which returns the actual value and an error message if it failed
+func (F *Frisby) expectCookie(kind, name string, expected interface{}, check func(cookie *http.Cookie) (interface{}, string)) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	cookie := F.responseCookie(name)
+	if cookie == nil {
+		F.addAssertion(kind, name, expected, nil, fmt.Sprintf("Expected Cookie %q, but it was missing", name))
+		return F
+	}
+	actual, err_str := check(cookie)
+	F.addAssertion(kind, name, expected, actual, err_str)
+	return F
+}
+
+// Checks the response sets the Cookie name to value
+func (F *Frisby) ExpectCookie(name, value string) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	return F.expectCookie("cookie", name, value, func(cookie *http.Cookie) (interface{}, string) {
+		if cookie.Value != value {
+			return cookie.Value, fmt.Sprintf("Expected Cookie %q to be %q, but got %q", name, value, cookie.Value)
+		}
+		return cookie.Value, ""
+	})
+}
+
+// Checks the response sets the Cookie name with the Secure attribute
+func (F *Frisby) ExpectCookieSecure(name string) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	return F.expectCookie("cookie_secure", name, true, func(cookie *http.Cookie) (interface{}, string) {
+		if !cookie.Secure {
+			return false, fmt.Sprintf("Expected Cookie %q to be Secure", name)
+		}
+		return true, ""
+	})
+}
+
+// Checks the response sets the Cookie name with the HttpOnly attribute
+func (F *Frisby) ExpectCookieHttpOnly(name string) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	return F.expectCookie("cookie_httponly", name, true, func(cookie *http.Cookie) (interface{}, string) {
+		if !cookie.HttpOnly {
+			return false, fmt.Sprintf("Expected Cookie %q to be HttpOnly", name)
+		}
+		return true, ""
+	})
+}
+
+// the SameSite attribute values, as written in Set-Cookie
+var sameSiteNames = map[http.SameSite]string{
+	http.SameSiteDefaultMode: "",
+	http.SameSiteLaxMode:     "Lax",
+	http.SameSiteStrictMode:  "Strict",
+	http.SameSiteNoneMode:    "None",
+}
+
+// Checks the response sets the Cookie name with the SameSite attribute,
+// one of http.SameSiteLaxMode, http.SameSiteStrictMode or http.SameSiteNoneMode
+func (F *Frisby) ExpectCookieSameSite(name string, same_site http.SameSite) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	expected := sameSiteNames[same_site]
+	return F.expectCookie("cookie_samesite", name, expected, func(cookie *http.Cookie) (interface{}, string) {
+		actual := sameSiteNames[cookie.SameSite]
+		if actual != expected {
+			return actual, fmt.Sprintf("Expected Cookie %q to be SameSite=%s, but got %q", name, expected, actual)
+		}
+		return actual, ""
+	})
+}
+
+// Checks the response sets the Cookie name to expire, by Max-Age or Expires,
+// between min and max from now
+//
+// Session cookies, which expire with the browser, fail.
+// ex:  ExpectCookieExpiresIn("session", 23*time.Hour, 25*time.Hour)
+func (F *Frisby) ExpectCookieExpiresIn(name string, min, max time.Duration) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	expected := fmt.Sprintf("%v to %v", min, max)
+	return F.expectCookie("cookie_expires", name, expected, func(cookie *http.Cookie) (interface{}, string) {
+		var expires_in time.Duration
+		switch {
+		case cookie.MaxAge < 0:
+			return "expired", fmt.Sprintf("Expected Cookie %q to expire in %s, but it is expired", name, expected)
+		case cookie.MaxAge > 0:
+			expires_in = time.Duration(cookie.MaxAge) * time.Second
+		case !cookie.Expires.IsZero():
+			expires_in = time.Until(cookie.Expires)
+		default:
+			return "session", fmt.Sprintf("Expected Cookie %q to expire in %s, but it is a session cookie", name, expected)
+		}
+		expires_in = expires_in.Round(time.Second)
+		if expires_in < min || expires_in > max {
+			return expires_in.String(), fmt.Sprintf("Expected Cookie %q to expire in %s, but it expires in %v", name, expected, expires_in)
+		}
+		return expires_in.String(), ""
+	})
+}
@@ -0,0 +1,152 @@
+package frisby_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+func newSessionServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", MaxAge: 3600, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+			http.SetCookie(w, &http.Cookie{Name: "pref", Value: "dark", Path: "/", Secure: true, Expires: time.Now().Add(48 * time.Hour)})
+			http.SetCookie(w, &http.Cookie{Name: "flash", Value: "hello"})
+		case "/logout":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "", Path: "/", MaxAge: -1})
+		}
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			fmt.Fprint(w, `{"user": null}`)
+			return
+		}
+		fmt.Fprintf(w, `{"user": %q}`, cookie.Value)
+	}))
+}
+
+func TestSession(t *testing.T) {
+	ts := newSessionServer()
+	defer ts.Close()
+
+	session := frisby.NewSession()
+	G := frisby.NewSuite("Test Session")
+	G.PrintProgressDot = false
+	G.SetSession(session)
+
+	G.CreateT(t, "Test Session login").
+		Post(ts.URL+"/login").
+		Send().
+		ExpectCookie("session", "abc").
+		ExpectCookieHttpOnly("session").
+		ExpectCookieSameSite("session", http.SameSiteLaxMode).
+		ExpectCookieExpiresIn("session", 59*time.Minute, time.Hour).
+		ExpectCookieSecure("pref").
+		ExpectCookieExpiresIn("pref", 47*time.Hour, 49*time.Hour)
+
+	G.CreateT(t, "Test Session me").
+		Get(ts.URL+"/me").
+		Send().
+		ExpectJson("user", "abc")
+
+	if value, ok := session.Cookie(ts.URL, "session"); !ok || value != "abc" {
+		t.Errorf("Expected the session cookie to be %q, but got %q", "abc", value)
+	}
+
+	G.CreateT(t, "Test Session without jar").
+		SetSession(nil).
+		Get(ts.URL+"/me").
+		Send().
+		ExpectJson("user", nil)
+
+	G.CreateT(t, "Test Session without jar cookie").
+		SetSession(nil).
+		SetCookie("session", "manual").
+		Get(ts.URL+"/me").
+		Send().
+		ExpectJson("user", "manual")
+
+	G.CreateT(t, "Test Session logout").
+		Post(ts.URL + "/logout").
+		Send()
+	G.CreateT(t, "Test Session after logout").
+		Get(ts.URL+"/me").
+		Send().
+		ExpectJson("user", nil)
+
+	u, _ := url.Parse(ts.URL)
+	session.SetCookies(u, []*http.Cookie{{Name: "session", Value: "xyz"}})
+	G.CreateT(t, "Test Session set").
+		Get(ts.URL+"/me").
+		Send().
+		ExpectJson("user", "xyz")
+
+	session.Clear()
+	G.CreateT(t, "Test Session cleared").
+		Get(ts.URL+"/me").
+		Send().
+		ExpectJson("user", nil)
+
+	G.SetSession(nil).SetCookie("session", "suite")
+	G.CreateT(t, "Test Session suite without jar").
+		Get(ts.URL+"/me").
+		Send().
+		ExpectJson("user", "suite")
+}
+
+func TestWithoutSession(t *testing.T) {
+	ts := newSessionServer()
+	defer ts.Close()
+
+	G := frisby.NewSuite("Test without Session")
+	G.PrintProgressDot = false
+
+	G.CreateT(t, "Test without Session login").
+		Post(ts.URL+"/login").
+		Send().
+		ExpectCookie("session", "abc")
+
+	G.CreateT(t, "Test without Session me").
+		Get(ts.URL+"/me").
+		Send().
+		ExpectJson("user", nil)
+}
+
+func TestExpectCookieFailures(t *testing.T) {
+	ts := newSessionServer()
+	defer ts.Close()
+
+	F := frisby.Create("Test ExpectCookie failures").
+		Post(ts.URL+"/login").
+		Send().
+		ExpectCookie("session", "xyz").
+		ExpectCookie("missing", "").
+		ExpectCookieSecure("session").
+		ExpectCookieHttpOnly("pref").
+		ExpectCookieSameSite("pref", http.SameSiteStrictMode).
+		ExpectCookieExpiresIn("session", 2*time.Hour, 3*time.Hour).
+		ExpectCookieExpiresIn("flash", 0, time.Hour)
+
+	expected := []string{
+		`Expected Cookie "session" to be "xyz", but got "abc"`,
+		`Expected Cookie "missing", but it was missing`,
+		`Expected Cookie "session" to be Secure`,
+		`Expected Cookie "pref" to be HttpOnly`,
+		`Expected Cookie "pref" to be SameSite=Strict, but got ""`,
+		`Expected Cookie "session" to expire in 2h0m0s to 3h0m0s, but it expires in 1h0m0s`,
+		`Expected Cookie "flash" to expire in 0s to 1h0m0s, but it is a session cookie`,
+	}
+	if len(F.Errs) != len(expected) {
+		t.Fatalf("Expected %d errors, but got %v", len(expected), F.Errs)
+	}
+	for i, err := range F.Errs {
+		if err.Error() != expected[i] {
+			t.Errorf("Expected error %q, but got %q", expected[i], err)
+		}
+	}
+}
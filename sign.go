@@ -0,0 +1,353 @@
+package frisby
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Signer signs a request in Send(), after its body, headers and
+// any AuthProvider token are final, just before it is sent
+//
+// Set it with Global.SetSigner() or Frisby.SetSigner().
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// SignerFunc is a function used as a Signer
+type SignerFunc func(req *http.Request, body []byte) error
+
+func (S SignerFunc) Sign(req *http.Request, body []byte) error {
+	return S(req, body)
+}
+
+// signTransport signs every request sent through it
+type signTransport struct {
+	signer Signer
+	next   http.RoundTripper
+}
+
+func (S *signTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the signer may change the headers of its own copy
+	signed := req.Clone(req.Context())
+	if body != nil {
+		signed.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if err := S.signer.Sign(signed, body); err != nil {
+		return nil, fmt.Errorf("signing request: %v", err)
+	}
+
+	next := S.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(signed)
+}
+
+// sign installs the Signer on the client of the Frisby object
+// and returns a function restoring the client
+func (F *Frisby) sign() func() {
+	return F.wrapTransport(func(next http.RoundTripper) http.RoundTripper {
+		return &signTransport{signer: F.Signer, next: next}
+	})
+}
+
+// Set the Signer of the coming requests
+//
+// Frisby objects created after this call use it
+func (G *Suite) SetSigner(signer Signer) *Suite {
+	G.Signer = signer
+	return G
+}
+
+// Set the Signer of the coming request
+//
+// Set it to nil to send the request without the Suite signer
+func (F *Frisby) SetSigner(signer Signer) *Frisby {
+	F.Signer = signer
+	return F
+}
+
+// AWSSigner signs requests with AWS Signature Version 4
+type AWSSigner struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Region       string
+	Service      string
+
+	// the signing time, time.Now when nil
+	Clock func() time.Time
+}
+
+// NewAWSSigner returns a Signer for the AWS service in the given region
+func NewAWSSigner(access_key, secret_key, region, service string) *AWSSigner {
+	return &AWSSigner{AccessKey: access_key, SecretKey: secret_key, Region: region, Service: service}
+}
+
+// headers which proxies or the transport may change, so they are not signed
+var awsUnsignedHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+	"content-length":  true,
+}
+
+func (S *AWSSigner) Sign(req *http.Request, body []byte) error {
+	now := time.Now
+	if S.Clock != nil {
+		now = S.Clock
+	}
+	t := now().UTC()
+	amz_date := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	payload_hash := hashHex(sha256.New, body)
+	req.Header.Set("X-Amz-Date", amz_date)
+	if S.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", S.SessionToken)
+	}
+	if S.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payload_hash)
+	}
+
+	// S3 paths are encoded once, the paths of other services twice
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	if S.Service != "s3" {
+		uri = awsEscape(uri, false)
+	}
+
+	query := req.URL.Query()
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsEscape(key, true)+"="+awsEscape(value, true))
+		}
+	}
+	sort.Strings(pairs)
+
+	headers := map[string]string{"host": requestHost(req)}
+	for key, values := range req.Header {
+		name := strings.ToLower(key)
+		if awsUnsignedHeaders[name] {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+	names := sortedKeys(headers)
+	canonical_headers := ""
+	for _, name := range names {
+		canonical_headers += name + ":" + headers[name] + "\n"
+	}
+	signed_headers := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		uri,
+		strings.Join(pairs, "&"),
+		canonical_headers,
+		signed_headers,
+		payload_hash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, S.Region, S.Service, "aws4_request"}, "/")
+	string_to_sign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amz_date,
+		scope,
+		hashHex(sha256.New, []byte(canonical)),
+	}, "\n")
+
+	key := []byte("AWS4" + S.SecretKey)
+	for _, part := range []string{date, S.Region, S.Service, "aws4_request"} {
+		key = hmacSum(sha256.New, key, []byte(part))
+	}
+	signature := hex.EncodeToString(hmacSum(sha256.New, key, []byte(string_to_sign)))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		S.AccessKey, scope, signed_headers, signature))
+	return nil
+}
+
+// HMACSigner signs requests with an HMAC of a canonical request,
+// for API gateways with their own signing scheme
+//
+// The default canonical request is made of lines with the method, the
+// escaped path and query, the lowercased "name:value" of each SignedHeader,
+// and the hex SHA-256 of the body. When TimestampHeader is set, it is set
+// to the signing time and signed first.
+type HMACSigner struct {
+	KeyId  string
+	Secret string
+
+	// sha256 when empty, or sha1 or sha512
+	Algorithm string
+
+	SignedHeaders []string
+
+	// set to the signing time, formatted with TimestampFormat,
+	// or as Unix seconds when it is empty
+	TimestampHeader string
+	TimestampFormat string
+
+	// the header of the signature, Authorization when empty
+	Header string
+
+	// the header value, with {key_id}, {algorithm}, {headers} and
+	// {signature} placeholders, see DefaultHMACFormat
+	Format string
+
+	// hex when empty, or base64
+	Encoding string
+
+	// replaces the default canonical request when set
+	Canonical func(req *http.Request, body []byte, signed_headers []string) string
+
+	// the signing time, time.Now when nil
+	Clock func() time.Time
+}
+
+const DefaultHMACFormat = "HMAC-{algorithm} KeyId={key_id}, SignedHeaders={headers}, Signature={signature}"
+
+// NewHMACSigner returns an HMACSigner using SHA-256 and the given signed headers
+func NewHMACSigner(key_id, secret string, signed_headers ...string) *HMACSigner {
+	return &HMACSigner{KeyId: key_id, Secret: secret, SignedHeaders: signed_headers}
+}
+
+var hmacHashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func (S *HMACSigner) Sign(req *http.Request, body []byte) error {
+	algorithm := strings.ToLower(S.Algorithm)
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	hash_func, ok := hmacHashes[algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported HMAC algorithm %q", S.Algorithm)
+	}
+
+	signed_headers := S.SignedHeaders
+	if S.TimestampHeader != "" {
+		now := time.Now
+		if S.Clock != nil {
+			now = S.Clock
+		}
+		timestamp := fmt.Sprint(now().Unix())
+		if S.TimestampFormat != "" {
+			timestamp = now().UTC().Format(S.TimestampFormat)
+		}
+		req.Header.Set(S.TimestampHeader, timestamp)
+		signed_headers = append([]string{S.TimestampHeader}, signed_headers...)
+	}
+
+	var canonical string
+	if S.Canonical != nil {
+		canonical = S.Canonical(req, body, signed_headers)
+	} else {
+		lines := []string{req.Method, req.URL.RequestURI()}
+		for _, name := range signed_headers {
+			value := req.Header.Get(name)
+			if strings.EqualFold(name, "host") {
+				value = requestHost(req)
+			}
+			lines = append(lines, strings.ToLower(name)+":"+strings.TrimSpace(value))
+		}
+		lines = append(lines, hashHex(sha256.New, body))
+		canonical = strings.Join(lines, "\n")
+	}
+
+	sum := hmacSum(hash_func, []byte(S.Secret), []byte(canonical))
+	signature := hex.EncodeToString(sum)
+	if S.Encoding == "base64" {
+		signature = base64.StdEncoding.EncodeToString(sum)
+	}
+
+	names := make([]string, len(signed_headers))
+	for i, name := range signed_headers {
+		names[i] = strings.ToLower(name)
+	}
+	format := S.Format
+	if format == "" {
+		format = DefaultHMACFormat
+	}
+	header := S.Header
+	if header == "" {
+		header = "Authorization"
+	}
+	req.Header.Set(header, strings.NewReplacer(
+		"{key_id}", S.KeyId,
+		"{algorithm}", strings.ToUpper(algorithm),
+		"{headers}", strings.Join(names, ";"),
+		"{signature}", signature,
+	).Replace(format))
+	return nil
+}
+
+// requestHost returns the Host header the request is sent with
+func requestHost(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+func hashHex(hash_func func() hash.Hash, data []byte) string {
+	h := hash_func()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hmacSum(hash_func func() hash.Hash, key, data []byte) []byte {
+	mac := hmac.New(hash_func, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// awsEscape percent-encodes every byte but the unreserved characters,
+// and '/' unless escape_slash
+func awsEscape(str string, escape_slash bool) string {
+	var buf strings.Builder
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !escape_slash {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
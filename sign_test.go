@@ -0,0 +1,98 @@
+package frisby_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestAWSSigner(t *testing.T) {
+	// from the AWS Signature Version 4 test suite
+	signer := frisby.NewAWSSigner("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service")
+	signer.Clock = func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) }
+
+	tests := []struct {
+		method, url string
+		signature   string
+	}{
+		{"GET", "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"GET", "https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, test.url, nil)
+		if err := signer.Sign(req, nil); err != nil {
+			t.Fatal(err)
+		}
+		expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+			"SignedHeaders=host;x-amz-date, Signature=" + test.signature
+		if auth := req.Header.Get("Authorization"); auth != expected {
+			t.Errorf("Expected %s %s to be signed with\n  %s\nbut got\n  %s", test.method, test.url, expected, auth)
+		}
+		if date := req.Header.Get("X-Amz-Date"); date != "20150830T123600Z" {
+			t.Errorf("Expected X-Amz-Date to be set, but got %q", date)
+		}
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	secret := "s3cret"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		body_hash := sha256.Sum256(body)
+		canonical := strings.Join([]string{
+			r.Method,
+			r.URL.RequestURI(),
+			"x-timestamp:" + r.Header.Get("X-Timestamp"),
+			"content-type:" + r.Header.Get("Content-Type"),
+			hex.EncodeToString(body_hash[:]),
+		}, "\n")
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(canonical))
+		expected := fmt.Sprintf("HMAC-SHA256 KeyId=key-1, SignedHeaders=x-timestamp;content-type, Signature=%x", mac.Sum(nil))
+		if r.Header.Get("Authorization") != expected {
+			w.WriteHeader(403)
+		}
+		fmt.Fprintf(w, `{"timestamp": %q}`, r.Header.Get("X-Timestamp"))
+	}))
+	defer ts.Close()
+
+	signer := frisby.NewHMACSigner("key-1", secret, "Content-Type")
+	signer.TimestampHeader = "X-Timestamp"
+	signer.Clock = func() time.Time { return time.Unix(1500000000, 0) }
+
+	G := frisby.NewSuite("Test HMACSigner")
+	G.PrintProgressDot = false
+	G.SetSigner(signer)
+
+	G.CreateT(t, "Test HMACSigner json").
+		Post(ts.URL+"/orders?id=1").
+		SetJson(map[string]string{"item": "frisby"}).
+		Send().
+		ExpectStatus(200).
+		ExpectJson("timestamp", "1500000000")
+
+	G.CreateT(t, "Test HMACSigner unsigned").
+		SetSigner(nil).
+		Get(ts.URL + "/orders").
+		Send().
+		ExpectStatus(403)
+
+	F := G.Create("Test HMACSigner error").
+		SetSigner(frisby.SignerFunc(func(req *http.Request, body []byte) error {
+			return fmt.Errorf("no key")
+		})).
+		Get(ts.URL + "/orders").
+		Send()
+	if F.Error() == nil || !strings.Contains(F.Error().Error(), "signing request: no key") {
+		t.Errorf("Expected the signing error, but got %v", F.Error())
+	}
+}
@@ -0,0 +1,323 @@
+package frisby
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Spec describes a set of requests and expectations as data
+//
+// Specs are written in YAML or JSON, see spec.schema.json for the format.
+type Spec struct {
+	Name   string                 `json:"name,omitempty"`
+	Global *SpecRequest           `json:"global,omitempty"`
+	Vars   map[string]interface{} `json:"vars,omitempty"`
+	Tests  []SpecTest             `json:"tests"`
+}
+
+// SpecRequest holds the Pre-flight options of a request
+//
+// It is used for the Global settings and each SpecTest.
+type SpecRequest struct {
+	BasicAuth *SpecBasicAuth    `json:"basic_auth,omitempty"`
+	Proxy     string            `json:"proxy,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Cookies   map[string]string `json:"cookies,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+	Json      interface{}       `json:"json,omitempty"`
+	Files     map[string]string `json:"files,omitempty"`
+}
+
+// SpecBasicAuth holds BasicAuth values
+type SpecBasicAuth struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// SpecTest describes a single request, its expectations and captures
+type SpecTest struct {
+	SpecRequest
+
+	Name   string      `json:"name"`
+	Method string      `json:"method"`
+	Url    string      `json:"url"`
+	Body   string      `json:"body,omitempty"`
+	Expect *SpecExpect `json:"expect,omitempty"`
+
+	Capture        map[string]string `json:"capture,omitempty"`
+	CaptureHeaders map[string]string `json:"capture_headers,omitempty"`
+	CaptureCookies map[string]string `json:"capture_cookies,omitempty"`
+}
+
+// SpecExpect holds the Post-flight expectations of a SpecTest
+type SpecExpect struct {
+	Status     int                    `json:"status,omitempty"`
+	Headers    map[string]string      `json:"headers,omitempty"`
+	Content    []string               `json:"content,omitempty"`
+	Json       map[string]interface{} `json:"json,omitempty"`
+	JsonTypes  map[string]string      `json:"json_types,omitempty"`
+	JsonLength map[string]int         `json:"json_length,omitempty"`
+}
+
+// type names usable in SpecExpect.JsonTypes
+var specJsonTypes = map[string]reflect.Kind{
+	"string":  reflect.String,
+	"number":  reflect.Float64,
+	"boolean": reflect.Bool,
+	"object":  reflect.Map,
+	"array":   reflect.Slice,
+	"null":    reflect.Invalid,
+}
+
+// specMethod matches the methods usable in SpecTest.Method, any HTTP token
+var specMethod = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// specStandardMethods are written in any case, other methods are case-sensitive
+var specStandardMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "HEAD": true, "OPTIONS": true,
+}
+
+// standardMethod uppercases method when it is one of specStandardMethods,
+// and keeps other methods as written
+func standardMethod(method string) string {
+	if specStandardMethods[strings.ToUpper(method)] {
+		return strings.ToUpper(method)
+	}
+	return method
+}
+
+// LoadSpec reads a YAML or JSON spec from the given file
+func LoadSpec(filename string) (*Spec, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if spec.Name == "" {
+		spec.Name = filename
+	}
+	return spec, nil
+}
+
+// ParseSpec parses a YAML or JSON spec
+func ParseSpec(data []byte) (*Spec, error) {
+	json_data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// numbers are kept as json.Number to compare equal to the responses
+	dec := json.NewDecoder(bytes.NewReader(json_data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+
+	spec := new(Spec)
+	if err := dec.Decode(spec); err != nil {
+		return nil, err
+	}
+
+	for i, test := range spec.Tests {
+		if test.Name == "" {
+			return nil, fmt.Errorf("test %d is missing a name", i)
+		}
+		if test.Url == "" {
+			return nil, fmt.Errorf("test %q is missing a url", test.Name)
+		}
+		if test.Method != "" && !specMethod.MatchString(test.Method) {
+			return nil, fmt.Errorf("test %q has unsupported method %q", test.Name, test.Method)
+		}
+		if test.Body != "" && (test.Json != nil || len(test.Data) > 0 || len(test.Files) > 0) {
+			return nil, fmt.Errorf("test %q has a body together with json, data or files", test.Name)
+		}
+		if test.Expect != nil {
+			for path, type_name := range test.Expect.JsonTypes {
+				if _, ok := specJsonTypes[type_name]; !ok {
+					return nil, fmt.Errorf("test %q has unknown json type %q for %q", test.Name, type_name, path)
+				}
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// Run executes the tests of the Spec in order in frisby.Global
+//
+// See RunSuite() for details
+func (S *Spec) Run() []*Frisby {
+	return S.RunSuite(Global)
+}
+
+// RunSuite executes the tests of the Spec in order in the given Suite
+//
+// The Global settings and Vars of the Spec are applied to the Suite
+// first, then a Frisby object is created, sent and checked for each test.
+func (S *Spec) RunSuite(G *Suite) []*Frisby {
+	if S.Global != nil {
+		S.Global.applyGlobal(G)
+	}
+	for name, value := range S.Vars {
+		G.SetVar(name, value)
+	}
+
+	frisbies := make([]*Frisby, 0, len(S.Tests))
+	for _, test := range S.Tests {
+		frisbies = append(frisbies, test.RunSuite(G))
+	}
+	return frisbies
+}
+
+// Run executes a single test of a Spec in frisby.Global
+func (T *SpecTest) Run() *Frisby {
+	return T.RunSuite(Global)
+}
+
+// RunSuite executes a single test of a Spec in the given Suite
+func (T *SpecTest) RunSuite(G *Suite) *Frisby {
+	F := T.create(G)
+
+	F.Send()
+	if F.Resp == nil {
+		// Send errors are only recorded on the Frisby
+		if err := F.Error(); err != nil {
+			G.AddError(F.Name, err.Error())
+		}
+		return F
+	}
+
+	if T.Expect != nil {
+		T.Expect.check(F)
+	}
+
+	for _, name := range sortedKeys(T.Capture) {
+		F.Capture(name, T.Capture[name])
+	}
+	for _, name := range sortedKeys(T.CaptureHeaders) {
+		F.CaptureHeader(name, T.CaptureHeaders[name])
+	}
+	for _, name := range sortedKeys(T.CaptureCookies) {
+		F.CaptureCookie(name, T.CaptureCookies[name])
+	}
+
+	return F
+}
+
+// create makes the Frisby object of the test, ready to Send()
+func (T *SpecTest) create(G *Suite) *Frisby {
+	F := G.Create(T.Name)
+	F.Method = standardMethod(T.Method)
+	if F.Method == "" {
+		F.Method = "GET"
+	}
+	F.Url = T.Url
+	T.SpecRequest.applyFrisby(F)
+	if T.Body != "" {
+		F.SetBodyString("", T.Body)
+	}
+	return F
+}
+
+func (E *SpecExpect) check(F *Frisby) {
+	if E.Status != 0 {
+		F.ExpectStatus(E.Status)
+	}
+	for _, key := range sortedKeys(E.Headers) {
+		F.ExpectHeader(key, E.Headers[key])
+	}
+	for _, content := range E.Content {
+		F.ExpectContent(content)
+	}
+	for _, path := range sortedKeys(E.Json) {
+		F.ExpectJson(path, E.Json[path])
+	}
+	for _, path := range sortedKeys(E.JsonTypes) {
+		if E.JsonTypes[path] == "number" {
+			F.Expect(expectJsonNumber(path))
+		} else {
+			F.ExpectJsonType(path, specJsonTypes[E.JsonTypes[path]])
+		}
+	}
+	for _, path := range sortedKeys(E.JsonLength) {
+		F.ExpectJsonLength(path, E.JsonLength[path])
+	}
+}
+
+// expectJsonNumber checks for a number at path, which
+// the response JSON holds as a json.Number
+func expectJsonNumber(path string) ExpectFunc {
+	return func(F *Frisby) (bool, string) {
+		matches, err := F.responseJson(path)
+		if err != nil {
+			return false, err.Error()
+		}
+		for _, value := range matches {
+			if _, ok := value.(json.Number); !ok {
+				return false, fmt.Sprintf("Expect Json %q type to be \"number\", but got %T", path, value)
+			}
+		}
+		return true, ""
+	}
+}
+
+func (R *SpecRequest) applyFrisby(F *Frisby) {
+	if R.BasicAuth != nil {
+		F.BasicAuth(R.BasicAuth.User, R.BasicAuth.Password)
+	}
+	if R.Proxy != "" {
+		F.SetProxy(R.Proxy)
+	}
+	F.SetHeaders(R.Headers)
+	F.SetCookies(R.Cookies)
+	F.SetDatas(R.Data)
+	F.SetParams(R.Params)
+	if R.Json != nil {
+		F.SetJson(R.Json)
+	}
+	for _, key := range sortedKeys(R.Files) {
+		F.AddFileByKey(key, R.Files[key])
+	}
+}
+
+func (R *SpecRequest) applyGlobal(G *Suite) {
+	if R.BasicAuth != nil {
+		G.BasicAuth(R.BasicAuth.User, R.BasicAuth.Password)
+	}
+	if R.Proxy != "" {
+		G.SetProxy(R.Proxy)
+	}
+	G.SetHeaders(R.Headers)
+	G.SetCookies(R.Cookies)
+	G.SetDatas(R.Data)
+	G.SetParams(R.Params)
+	if R.Json != nil {
+		G.SetJson(R.Json)
+	}
+	for _, key := range sortedKeys(R.Files) {
+		G.AddFileByKey(key, R.Files[key])
+	}
+}
+
+// sortedKeys returns the keys of a string keyed map in sorted order
+// so the expectations of a spec run deterministically
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	sorted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, key.String())
+	}
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,99 @@
+package frisby_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+const testSpec = `
+name: Users API
+vars:
+  user: frisby
+tests:
+  - name: Test spec login
+    method: post
+    url: %[1]s/login
+    headers:
+      Authorization: Basic {{user}}
+    expect:
+      status: 200
+      json:
+        path: /login
+        auth: Basic frisby
+      json_types:
+        data: object
+        data.auth.token: string
+    capture:
+      token: data.auth.token
+  - name: Test spec fetch
+    url: %[1]s/users
+    params:
+      q: "{{token}}"
+    expect:
+      status: 404
+      content:
+        - '"query":"secret"'
+`
+
+func TestSpec(t *testing.T) {
+	ts := newEchoServer()
+	defer ts.Close()
+
+	spec, err := frisby.ParseSpec([]byte(fmt.Sprintf(testSpec, ts.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Name != "Users API" || len(spec.Tests) != 2 {
+		t.Fatalf("Unexpected spec %+v", spec)
+	}
+
+	frisbies := spec.Run()
+	if errs := frisbies[0].Errors(); len(errs) != 0 {
+		t.Errorf("Expected no errors, but got %v", errs)
+	}
+
+	errs := frisbies[1].Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "Expected Status 404, but got 200") {
+		t.Errorf("Expected a single status error, but got %v", errs)
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	for _, spec := range []string{
+		`tests: [{url: "http://localhost"}]`,
+		`tests: [{name: missing url}]`,
+		`tests: [{name: bad method, method: "GET /", url: "http://localhost"}]`,
+		`tests: [{name: bad type, url: "http://localhost", expect: {json_types: {a: integer}}}]`,
+		`tests: [{name: unknown field, url: "http://localhost", expects: {}}]`,
+		`tests: [{name: body and json, url: "http://localhost", body: raw, json: {a: 1}}]`,
+		`tests: [{name: body and data, url: "http://localhost", body: raw, data: {a: b}}]`,
+	} {
+		if _, err := frisby.ParseSpec([]byte(spec)); err == nil {
+			t.Errorf("Expected an error parsing %q", spec)
+		}
+	}
+}
+
+func TestSpecSchema(t *testing.T) {
+	schema, err := frisby.LoadJsonSchema("spec.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for spec, violations := range map[string]int{
+		`{"global": {"headers": {"a": "b"}}, "tests": [{"name": "a", "url": "http://localhost", "params": {"q": "1"}}]}`: 0,
+		`{"global": {"header": {"a": "b"}}, "tests": []}`:                                                                1,
+		`{"tests": [{"name": "a", "url": "http://localhost", "expects": {}}]}`:                                           1,
+	} {
+		var v interface{}
+		if err := json.Unmarshal([]byte(spec), &v); err != nil {
+			t.Fatal(err)
+		}
+		if errs := schema.Validate(v); len(errs) != violations {
+			t.Errorf("Expected %d violations of %s, but got %q", violations, spec, errs)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package frisby
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/mozillazg/request"
+)
+
+// Suite owns the default settings, client, variables, counters and
+// errors shared by the Frisby objects it creates
+//
+// Global is the default Suite, used by Create(). Separate Suites keep
+// groups of tests, like different services or tenants, from sharing
+// configuration.
+type Suite struct {
+	Name string
+
+	// guards the counters, Errs, Frisbies, Vars and the JSON lines
+	// stream, which may be updated by Frisby objects in several goroutines
+	mu sync.Mutex
+
+	Req  *request.Request
+	Errs map[string][]error
+
+	// every Frisby object made by Create(), in order, when KeepFrisbies,
+	// PrintCurlOnFail or Budgets need them
+	Frisbies []*Frisby
+
+	// keep every Frisby object in Frisbies, for the JUnit and JSON reports
+	//
+	// It is off by default, so long runs don't hold on to every request
+	// and response. The counters of the summaries work without it.
+	KeepFrisbies bool
+
+	NumRequest int
+	NumAsserts int
+	NumErrored int
+
+	// totals of the summaries, which don't need Frisbies
+	numFrisbies   int
+	numFailed     int
+	executionTime float64
+
+	PrintProgressName bool
+	PrintProgressDot  bool
+
+	// add the curl command of failed requests to PrintReport(),
+	// which keeps the Frisby objects like KeepFrisbies
+	PrintCurlOnFail bool
+
+	PathSeparator string
+
+	// set by StreamJsonLines() to write events as they happen
+	jsonLines io.Writer
+
+	// the transport cloned by the TLS options
+	tlsTransport *http.Transport
+
+	// copied into each Frisby object by Create()
+	SendOptions
+
+	// variables stored by Capture() for {{name}} placeholders
+	Vars map[string]interface{}
+
+	// response time budgets checked by PrintReport() and the JSON reports
+	Budgets []Budget
+}
+
+// SendOptions are the settings used by Send(), which each
+// Frisby object and load test iteration copies from its Suite
+type SendOptions struct {
+	// validates the request and response in Send() when set
+	Validator Validator
+
+	// records or replays the request in Send() when set
+	Cassette *Cassette
+
+	// archives the request and response in Send() when set
+	Har *Har
+
+	// adds a bearer token to the request in Send() when set
+	Auth AuthProvider
+
+	// signs the request in Send() when set
+	Signer Signer
+
+	// answers Digest challenges in Send() when set
+	Digest *DigestCredentials
+}
+
+const DefaultPathSeparator = "."
+
+// the default Suite, used by Create()
+var Global = NewSuite("Global")
+
+// Creates a new Suite with the given name and default settings
+func NewSuite(name string) *Suite {
+	G := new(Suite)
+	G.Name = name
+	G.Req = request.NewRequest(new(http.Client))
+	// without a Session, each Frisby object gets its own cookie jar
+	G.Req.Client.Jar = nil
+	G.Errs = make(map[string][]error, 0)
+	G.Vars = make(map[string]interface{})
+	G.PrintProgressDot = true
+	G.PathSeparator = DefaultPathSeparator
+	return G
+}
+
+// Set BasicAuth values for the coming request
+func (G *Suite) BasicAuth(user, passwd string) *Suite {
+	G.Req.BasicAuth = request.BasicAuth{Username: user, Password: passwd}
+	return G
+}
+
+// Set Proxy URL for the coming request
+func (G *Suite) SetProxy(url string) *Suite {
+	G.Req.Proxy = url
+	return G
+}
+
+// Set a Header value for the coming request
+func (G *Suite) SetHeader(key, value string) *Suite {
+	if G.Req.Headers == nil {
+		G.Req.Headers = make(map[string]string)
+	}
+	G.Req.Headers[key] = value
+	return G
+}
+
+// Set several Headers for the coming request
+func (G *Suite) SetHeaders(headers map[string]string) *Suite {
+	if G.Req.Headers == nil {
+		G.Req.Headers = make(map[string]string)
+	}
+	for key, value := range headers {
+		G.Req.Headers[key] = value
+	}
+	return G
+}
+
+// Set a Cookie value for the coming request
+func (G *Suite) SetCookie(key, value string) *Suite {
+	if G.Req.Cookies == nil {
+		G.Req.Cookies = make(map[string]string)
+	}
+	G.Req.Cookies[key] = value
+	return G
+}
+
+// Set several Cookie values for the coming request
+func (G *Suite) SetCookies(cookies map[string]string) *Suite {
+	if G.Req.Cookies == nil {
+		G.Req.Cookies = make(map[string]string)
+	}
+	for key, value := range cookies {
+		G.Req.Cookies[key] = value
+	}
+	return G
+}
+
+// Set a Gorm data for the coming request
+func (G *Suite) SetData(key, value string) *Suite {
+	if G.Req.Data == nil {
+		G.Req.Data = make(map[string]string)
+	}
+	G.Req.Data[key] = value
+	return G
+}
+
+// Set several Gorm data for the coming request
+func (G *Suite) SetDatas(datas map[string]string) *Suite {
+	if G.Req.Data == nil {
+		G.Req.Data = make(map[string]string)
+	}
+	for key, value := range datas {
+		G.Req.Data[key] = value
+	}
+	return G
+}
+
+// Set a url Param for the coming request
+func (G *Suite) SetParam(key, value string) *Suite {
+	if G.Req.Params == nil {
+		G.Req.Params = make(map[string]string)
+	}
+	G.Req.Params[key] = value
+	return G
+}
+
+// Set several url Param for the coming request
+func (G *Suite) SetParams(params map[string]string) *Suite {
+	// Params stays nil without any, so no empty query is added
+	if G.Req.Params == nil && len(params) > 0 {
+		G.Req.Params = make(map[string]string)
+	}
+	for key, value := range params {
+		G.Req.Params[key] = value
+	}
+	return G
+}
+
+// Set the JSON body for the coming request
+func (G *Suite) SetJson(json interface{}) *Suite {
+	G.Req.Json = json
+	return G
+}
+
+// Add a file to the Gorm data for the coming request
+func (G *Suite) AddFile(filename string) *Suite {
+	file, err := os.Open(filename)
+	if err != nil {
+		G.AddError(G.Name, err.Error())
+		fmt.Println("Error adding file to global")
+	} else {
+		fileField := request.FileField{FieldName: "file", FileName: filename, File: file}
+		G.Req.Files = append(G.Req.Files, fileField)
+	}
+	return G
+}
+
+// Set a variable for {{name}} placeholders in coming requests
+func (G *Suite) SetVar(name string, value interface{}) *Suite {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	if G.Vars == nil {
+		G.Vars = make(map[string]interface{})
+	}
+	G.Vars[name] = value
+	return G
+}
+
+// Get a variable set by SetVar() or Capture()
+func (G *Suite) GetVar(name string) (interface{}, bool) {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	value, ok := G.Vars[name]
+	return value, ok
+}
+
+// Add a file to the Form data with the given key for the coming request
+func (G *Suite) AddFileByKey(key, filename string) *Suite {
+	file, err := os.Open(filename)
+	if err != nil {
+		G.AddError(G.Name, err.Error())
+		fmt.Println("Error adding file to global")
+	} else {
+		if len(key) == 0 {
+			key = defaultFileKey
+		}
+		fileField := request.FileField{
+			FieldName: key,
+			FileName:  filepath.Base(filename),
+			File:      file}
+		G.Req.Files = append(G.Req.Files, fileField)
+	}
+	return G
+}
+
+// Manually add an error, if you need to
+func (G *Suite) AddError(name, err_str string) *Suite {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	G.NumErrored++
+	err := errors.New(err_str)
+	G.Errs[name] = append(G.Errs[name], err)
+	return G
+}
+
+// Get all errors for the Suite
+//
+// This function should be called last
+func (G *Suite) Errors() map[string][]error {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	errs := make(map[string][]error, len(G.Errs))
+	for name, val := range G.Errs {
+		errs[name] = append([]error(nil), val...)
+	}
+	return errs
+}
+
+// Prints a report for the Suite
+//
+// If there are any errors, they will all be printed as well
+func (G *Suite) PrintReport() *Suite {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	budget_errs := G.checkBudgets()
+	fmt.Printf("\nFor %d requests made\n", G.NumRequest)
+	if len(G.Errs) == 0 && len(budget_errs) == 0 {
+		fmt.Printf("  All tests passed\n")
+	} else {
+		fmt.Printf("  FAILED  [%d/%d]\n", G.NumErrored+len(budget_errs), G.NumAsserts+len(G.Budgets))
+		for _, key := range G.errNames() {
+			fmt.Printf("      [%s]\n", key)
+			for _, e := range G.Errs[key] {
+				fmt.Println("        - ", e)
+			}
+			if G.PrintCurlOnFail {
+				for _, F := range G.Frisbies {
+					if F.Name == key && len(F.Errs) > 0 {
+						fmt.Println(indent(F.AsCurl(), "        "))
+					}
+				}
+			}
+		}
+		if len(budget_errs) > 0 {
+			fmt.Printf("      [Budgets]\n")
+			for _, e := range budget_errs {
+				fmt.Println("        - ", e)
+			}
+		}
+	}
+
+	return G
+}
+
+// errNames returns the keys of Errs in the order the Frisby objects
+// were created, followed by any other keys sorted by name
+func (G *Suite) errNames() []string {
+	names := make([]string, 0, len(G.Errs))
+	seen := make(map[string]bool, len(G.Errs))
+	for _, F := range G.Frisbies {
+		if _, ok := G.Errs[F.Name]; ok && !seen[F.Name] {
+			seen[F.Name] = true
+			names = append(names, F.Name)
+		}
+	}
+	others := make([]string, 0)
+	for name := range G.Errs {
+		if !seen[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// frisbies returns a copy of Frisbies
+func (G *Suite) frisbies() []*Frisby {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	return append([]*Frisby(nil), G.Frisbies...)
+}
+
+// checkKept returns an error when Frisby objects were left out of Frisbies,
+// so a report of them would be missing tests
+func (G *Suite) checkKept() error {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	if missing := G.numFrisbies - len(G.Frisbies); missing > 0 {
+		return fmt.Errorf("Suite %q did not keep %d of its Frisby objects for the report, set KeepFrisbies", G.Name, missing)
+	}
+	return nil
+}
+
+func (G *Suite) addFrisby(F *Frisby) {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	G.numFrisbies++
+	if G.KeepFrisbies || G.PrintCurlOnFail || len(G.Budgets) > 0 {
+		G.Frisbies = append(G.Frisbies, F)
+	}
+}
+
+// countFailed counts a Frisby object which got its first error
+func (G *Suite) countFailed() {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	G.numFailed++
+}
+
+func (G *Suite) countTime(seconds float64) {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	G.executionTime += seconds
+}
+
+func (G *Suite) countRequest() {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	G.NumRequest++
+}
+
+func (G *Suite) countAssert() {
+	G.mu.Lock()
+	defer G.mu.Unlock()
+	G.NumAsserts++
+}
@@ -0,0 +1,56 @@
+package frisby_test
+
+import (
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func TestSuite(t *testing.T) {
+	ts := newEchoServer()
+	defer ts.Close()
+
+	requests := frisby.Global.NumRequest
+
+	tenant_a := frisby.NewSuite("tenant a").SetHeader("Authorization", "Bearer a").SetParam("q", "a")
+	tenant_b := frisby.NewSuite("tenant b").SetHeader("Authorization", "Bearer b")
+
+	tenant_a.CreateT(t, "Test Suite a").
+		Get(ts.URL).
+		Send().
+		ExpectJson("auth", "Bearer a").
+		ExpectJson("query", "a").
+		Capture("token", "auth")
+
+	tenant_b.CreateT(t, "Test Suite b").
+		Get(ts.URL).
+		Send().
+		ExpectJson("auth", "Bearer b")
+
+	F := tenant_b.Create("Test Suite b fails").
+		Get(ts.URL).
+		Send().
+		ExpectJson("auth", "Bearer a")
+	if F.Suite != tenant_b || len(F.Errs) != 1 {
+		t.Errorf("Expected 1 error in tenant b, but got %v", F.Errs)
+	}
+
+	if tenant_a.NumRequest != 1 || tenant_b.NumRequest != 2 {
+		t.Errorf("Expected 1 and 2 requests, but got %d and %d", tenant_a.NumRequest, tenant_b.NumRequest)
+	}
+	if tenant_a.NumErrored != 0 || tenant_b.NumErrored != 1 {
+		t.Errorf("Expected 0 and 1 errors, but got %d and %d", tenant_a.NumErrored, tenant_b.NumErrored)
+	}
+	if frisby.Global.NumRequest != requests {
+		t.Errorf("Expected no Global requests, but got %d", frisby.Global.NumRequest-requests)
+	}
+	if _, ok := tenant_b.GetVar("token"); ok {
+		t.Errorf("Expected tenant b not to have the variables of tenant a")
+	}
+	if summary := tenant_b.Summary(); summary.Frisbies != 2 || summary.Failed != 1 {
+		t.Errorf("Expected 2 Frisbies with 1 failed, but got %+v", summary)
+	}
+	if len(tenant_b.Frisbies) != 0 {
+		t.Errorf("Expected the Frisby objects not to be kept, but got %d", len(tenant_b.Frisbies))
+	}
+}
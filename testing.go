@@ -0,0 +1,65 @@
+package frisby
+
+import (
+	"testing"
+)
+
+// Creates a new Frisby object bound to the given *testing.T
+//
+// Every failed expectation is reported through t.Errorf and
+// a failed Send() stops the test with t.Fatalf, so the results
+// show up in go test, -run, -v, -json and IDE runners natively.
+func CreateT(t *testing.T, name string) *Frisby {
+	return Global.CreateT(t, name)
+}
+
+// Creates a new Frisby object in the Suite bound to the given *testing.T
+//
+// See CreateT() for details
+func (G *Suite) CreateT(t *testing.T, name string) *Frisby {
+	return G.Create(name).WithT(t)
+}
+
+// Bind the Frisby object to the given *testing.T
+//
+// See CreateT() for details
+func (F *Frisby) WithT(t *testing.T) *Frisby {
+	F.T = t
+	return F
+}
+
+// function type used as argument to Run()
+type RunFunc func(F *Frisby)
+
+// Run executes foo as a t.Run subtest with the given name
+//
+// foo is handed a Frisby object created with CreateT() for the subtest,
+// so each Frisby chain maps to its own go test entry.
+// It returns whether the subtest succeeded.
+func Run(t *testing.T, name string, foo RunFunc) bool {
+	t.Helper()
+	return Global.Run(t, name, foo)
+}
+
+// Run executes foo as a t.Run subtest with a Frisby object of the Suite
+//
+// See Run() for details
+func (G *Suite) Run(t *testing.T, name string, foo RunFunc) bool {
+	t.Helper()
+	return t.Run(name, func(t *testing.T) {
+		foo(G.CreateT(t, name))
+	})
+}
+
+// CheckBudgetsT reports every Budget exceeded by the Frisby objects
+// of the Suite through t.Errorf, and returns whether all of them passed
+//
+// Call it at the end of the test, ex: defer suite.CheckBudgetsT(t)
+func (G *Suite) CheckBudgetsT(t *testing.T) bool {
+	t.Helper()
+	errs := G.CheckBudgets()
+	for _, err := range errs {
+		t.Errorf("%s: %v", G.Name, err)
+	}
+	return len(errs) == 0
+}
@@ -0,0 +1,53 @@
+package frisby_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/verdverm/frisby"
+)
+
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"method": %q, "path": %q, "items": [1, 2, 3]}`, r.Method, r.URL.Path)
+	}))
+}
+
+func TestCreateT(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	F := frisby.CreateT(t, "Test CreateT").
+		Get(ts.URL+"/users").
+		Send().
+		ExpectStatus(200).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectJson("path", "/users").
+		ExpectJsonLength("items", 3)
+
+	if F.T != t {
+		t.Fatalf("Expected Frisby to be bound to the calling test")
+	}
+	if len(F.Errs) != 0 {
+		t.Fatalf("Expected no errors, but got %v", F.Errs)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	for _, method := range []string{"GET", "POST", "DELETE"} {
+		method := method
+		frisby.Run(t, "Test Run "+method, func(F *frisby.Frisby) {
+			F.Method = method
+			F.Url = ts.URL
+			F.Send().
+				ExpectStatus(200).
+				ExpectJson("method", method)
+		})
+	}
+}
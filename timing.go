@@ -0,0 +1,263 @@
+package frisby
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing is the breakdown of the last request sent by a Frisby object,
+// after any redirects
+//
+// DNS, Connect and TLS are zero when a kept-alive connection was Reused.
+// TTFB runs from the start of the request to the first response byte,
+// Transfer from there to the end of the body, and Total covers both.
+// Auth is the time of the 401 challenges answered before the request,
+// like the one of DigestAuth(), which Total does not cover.
+// In reports, durations are in seconds.
+type Timing struct {
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	TTFB     time.Duration
+	Transfer time.Duration
+	Total    time.Duration
+	Auth     time.Duration
+	Reused   bool
+}
+
+// the phases accepted by ExpectTiming()
+var timingPhases = []string{"dns", "connect", "tls", "ttfb", "transfer", "total", "auth"}
+
+// Phase returns the duration of the named phase, one of
+// dns, connect, tls, ttfb, transfer, total or auth
+func (T Timing) Phase(name string) (time.Duration, bool) {
+	switch name {
+	case "dns":
+		return T.DNS, true
+	case "connect":
+		return T.Connect, true
+	case "tls":
+		return T.TLS, true
+	case "ttfb":
+		return T.TTFB, true
+	case "transfer":
+		return T.Transfer, true
+	case "total":
+		return T.Total, true
+	case "auth":
+		return T.Auth, true
+	}
+	return 0, false
+}
+
+// timingJson is Timing as written in reports, in seconds
+type timingJson struct {
+	DNS      float64 `json:"dns"`
+	Connect  float64 `json:"connect"`
+	TLS      float64 `json:"tls"`
+	TTFB     float64 `json:"ttfb"`
+	Transfer float64 `json:"transfer"`
+	Total    float64 `json:"total"`
+	Auth     float64 `json:"auth,omitempty"`
+	Reused   bool    `json:"reused"`
+}
+
+func (T Timing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(timingJson{
+		T.DNS.Seconds(), T.Connect.Seconds(), T.TLS.Seconds(),
+		T.TTFB.Seconds(), T.Transfer.Seconds(), T.Total.Seconds(), T.Auth.Seconds(), T.Reused,
+	})
+}
+
+func (T *Timing) UnmarshalJSON(data []byte) error {
+	var tj timingJson
+	if err := json.Unmarshal(data, &tj); err != nil {
+		return err
+	}
+	seconds := func(s float64) time.Duration {
+		return time.Duration(s * float64(time.Second))
+	}
+	*T = Timing{
+		seconds(tj.DNS), seconds(tj.Connect), seconds(tj.TLS),
+		seconds(tj.TTFB), seconds(tj.Transfer), seconds(tj.Total), seconds(tj.Auth), tj.Reused,
+	}
+	return nil
+}
+
+// SentRequest is a request sent by Send(), which sends several
+// when it follows redirects or answers auth challenges
+//
+// Status is zero when the request failed, and Time is in seconds.
+type SentRequest struct {
+	Method string  `json:"method"`
+	Url    string  `json:"url"`
+	Status int     `json:"status,omitempty"`
+	Time   float64 `json:"time"`
+}
+
+// timer is an http.RoundTripper tracing every request sent through it,
+// until stop() hands the Timing and the requests to the Frisby object
+type timer struct {
+	next http.RoundTripper
+
+	// guards timing and sent, trace callbacks may run in other
+	// goroutines, even after the round trip returned
+	mu      sync.Mutex
+	timing  Timing
+	sent    []SentRequest
+	stopped bool
+
+	// set when the last response was a 401 challenge
+	challenged bool
+}
+
+// stop returns the Timing of the last request and every request sent,
+// later trace callbacks are ignored
+func (R *timer) stop() (Timing, []SentRequest) {
+	R.mu.Lock()
+	defer R.mu.Unlock()
+	R.stopped = true
+	return R.timing, R.sent
+}
+
+func (R *timer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var timing Timing
+	var dns_start, connect_start, tls_start time.Time
+	start := time.Now()
+
+	// a new round trip, as after a redirect, replaces the last timing,
+	// but one answering a challenge adds it to the Auth time
+	R.mu.Lock()
+	if R.challenged {
+		timing.Auth = R.timing.Auth + R.timing.Total
+	}
+	R.challenged = false
+	R.timing = timing
+	index := len(R.sent)
+	R.sent = append(R.sent, SentRequest{Method: req.Method, Url: req.URL.String()})
+	R.mu.Unlock()
+	update := func(foo func()) {
+		R.mu.Lock()
+		defer R.mu.Unlock()
+		if R.stopped {
+			return
+		}
+		foo()
+		R.timing = timing
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			update(func() { dns_start = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			update(func() { timing.DNS = time.Since(dns_start) })
+		},
+		ConnectStart: func(network, addr string) {
+			update(func() { connect_start = time.Now() })
+		},
+		ConnectDone: func(network, addr string, err error) {
+			update(func() { timing.Connect = time.Since(connect_start) })
+		},
+		TLSHandshakeStart: func() {
+			update(func() { tls_start = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			update(func() { timing.TLS = time.Since(tls_start) })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			update(func() { timing.Reused = info.Reused })
+		},
+		GotFirstResponseByte: func() {
+			update(func() { timing.TTFB = time.Since(start) })
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	next := R.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		update(func() { R.sent[index].Time = time.Since(start).Seconds() })
+		return nil, err
+	}
+
+	update(func() {
+		// transports without a connection, like handlers and cassettes
+		if timing.TTFB == 0 {
+			timing.TTFB = time.Since(start)
+		}
+		timing.Total = timing.TTFB
+		R.challenged = resp.StatusCode == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") != ""
+		R.sent[index].Status = resp.StatusCode
+		R.sent[index].Time = timing.Total.Seconds()
+	})
+	resp.Body = &timedBody{ReadCloser: resp.Body, done: func() {
+		update(func() {
+			timing.Total = time.Since(start)
+			timing.Transfer = timing.Total - timing.TTFB
+			R.sent[index].Time = timing.Total.Seconds()
+		})
+	}}
+	return resp, nil
+}
+
+// timedBody calls done once the body was read to the end or closed
+type timedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (B *timedBody) Read(p []byte) (int, error) {
+	n, err := B.ReadCloser.Read(p)
+	if err == io.EOF {
+		B.once.Do(B.done)
+	}
+	return n, err
+}
+
+func (B *timedBody) Close() error {
+	B.once.Do(B.done)
+	return B.ReadCloser.Close()
+}
+
+// trace installs a timer on the client of the Frisby object
+// and returns it along with a function restoring the client
+func (F *Frisby) trace() (*timer, func()) {
+	var R *timer
+	restore := F.wrapTransport(func(next http.RoundTripper) http.RoundTripper {
+		R = &timer{next: next}
+		return R
+	})
+	return R, restore
+}
+
+// Checks that a phase of the request Timing took at most max
+//
+// phase is one of dns, connect, tls, ttfb, transfer, total or auth,
+// see Timing for details.
+// ex:  ExpectTiming("ttfb", 200*time.Millisecond)
+func (F *Frisby) ExpectTiming(phase string, max time.Duration) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	actual, ok := F.Timing.Phase(phase)
+	err_str := ""
+	if !ok {
+		err_str = fmt.Sprintf("Unknown timing phase %q, expected one of %v", phase, timingPhases)
+	} else if actual > max {
+		err_str = fmt.Sprintf("Expected %s to take at most %v, but took %v", phase, max, actual)
+	}
+	F.addAssertion("timing", phase, max.Seconds(), actual.Seconds(), err_str)
+	return F
+}
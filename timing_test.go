@@ -0,0 +1,77 @@
+package frisby_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+func newSlowServer() *httptest.Server {
+	return httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"first": true`))
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte(`}`))
+	}))
+}
+
+func TestTiming(t *testing.T) {
+	ts := newSlowServer()
+	ts.StartTLS()
+	defer ts.Close()
+
+	F := frisby.CreateT(t, "Test Timing").
+		SetTransport(ts.Client().Transport).
+		Get(ts.URL).
+		Send().
+		ExpectJson("first", true).
+		ExpectTiming("ttfb", time.Second).
+		ExpectTiming("total", time.Second)
+
+	timing := F.Timing
+	if timing.Connect <= 0 || timing.TLS <= 0 || timing.Reused {
+		t.Errorf("Expected a new TLS connection, but got %+v", timing)
+	}
+	if timing.TTFB < 50*time.Millisecond || timing.Transfer < 30*time.Millisecond {
+		t.Errorf("Expected a slow first byte and transfer, but got %+v", timing)
+	}
+	if timing.Total != timing.TTFB+timing.Transfer {
+		t.Errorf("Expected the total to be the ttfb plus the transfer, but got %+v", timing)
+	}
+
+	F = frisby.Create("Test Timing fails").
+		SetTransport(ts.Client().Transport).
+		Get(ts.URL).
+		Send().
+		ExpectTiming("ttfb", time.Millisecond).
+		ExpectTiming("unknown", time.Second)
+	if len(F.Errs) != 2 {
+		t.Errorf("Expected 2 errors, but got %v", F.Errs)
+	}
+	if !F.Timing.Reused || F.Timing.TLS != 0 {
+		t.Errorf("Expected a reused connection, but got %+v", F.Timing)
+	}
+
+	data, err := json.Marshal(F.Report())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"timing":{"dns":0,"connect":0,"tls":0,"ttfb":0.0`)) {
+		t.Errorf("Expected the timing in seconds in the report, but got %s", data)
+	}
+}
+
+func TestTimingHandler(t *testing.T) {
+	frisby.CreateT(t, "Test Timing handler").
+		SetHandler(newTestHandler()).
+		Get("http://api.test/users/1").
+		Send().
+		ExpectTiming("connect", 0).
+		ExpectTiming("ttfb", time.Second)
+}
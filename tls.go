@@ -0,0 +1,324 @@
+package frisby
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// configureTLS changes the TLS config of the client transport, which must be
+// an *http.Transport, or nil for http.DefaultTransport
+//
+// The transport is cloned once, and kept in owned, so options never change
+// a transport set by the caller, or shared with the Suite.
+func configureTLS(client *http.Client, owned **http.Transport, configure func(config *tls.Config) error) error {
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t
+		if t != *owned {
+			transport = t.Clone()
+		}
+	default:
+		return fmt.Errorf("TLS options need an *http.Transport, but the client uses a %T", t)
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	if err := configure(transport.TLSClientConfig); err != nil {
+		return err
+	}
+	// connections made with the previous config are not used again
+	transport.CloseIdleConnections()
+	client.Transport = transport
+	*owned = transport
+	return nil
+}
+
+func setTLSConfig(config *tls.Config) func(*tls.Config) error {
+	return func(c *tls.Config) error {
+		*c = *config.Clone()
+		return nil
+	}
+}
+
+func addClientCert(cert_file, key_file string) func(*tls.Config) error {
+	return func(c *tls.Config) error {
+		cert, err := tls.LoadX509KeyPair(cert_file, key_file)
+		if err != nil {
+			return err
+		}
+		c.Certificates = append(c.Certificates, cert)
+		return nil
+	}
+}
+
+func addCAFile(filename string) func(*tls.Config) error {
+	return func(c *tls.Config) error {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		pool := c.RootCAs
+		if pool == nil {
+			if pool, err = x509.SystemCertPool(); err != nil {
+				pool = x509.NewCertPool()
+			}
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no PEM certificates in %s", filename)
+		}
+		c.RootCAs = pool
+		return nil
+	}
+}
+
+// Set the TLS config of the coming requests
+//
+// TLS options work with the default transport or an *http.Transport,
+// Frisby objects created after this call use them.
+func (G *Suite) SetTLSConfig(config *tls.Config) *Suite {
+	return G.configureTLS(setTLSConfig(config))
+}
+
+// Add a client certificate and key, from PEM files, for mutual TLS
+func (G *Suite) AddClientCert(cert_file, key_file string) *Suite {
+	return G.configureTLS(addClientCert(cert_file, key_file))
+}
+
+// Add the PEM certificates of a CA to the trusted ones, the system pool by default
+func (G *Suite) AddCAFile(filename string) *Suite {
+	return G.configureTLS(addCAFile(filename))
+}
+
+// Set the pool of CA certificates trusted instead of the system pool
+func (G *Suite) SetRootCAs(pool *x509.CertPool) *Suite {
+	return G.configureTLS(func(c *tls.Config) error {
+		c.RootCAs = pool
+		return nil
+	})
+}
+
+// Set the server name checked against the server certificate, and sent with SNI,
+// instead of the host of the URL
+func (G *Suite) SetServerName(name string) *Suite {
+	return G.configureTLS(func(c *tls.Config) error {
+		c.ServerName = name
+		return nil
+	})
+}
+
+// Set the min and max TLS versions, like tls.VersionTLS12, 0 for the defaults
+func (G *Suite) SetTLSVersions(min, max uint16) *Suite {
+	return G.configureTLS(func(c *tls.Config) error {
+		c.MinVersion, c.MaxVersion = min, max
+		return nil
+	})
+}
+
+// Accept any server certificate, for development servers only
+func (G *Suite) SetInsecureSkipVerify(skip bool) *Suite {
+	return G.configureTLS(func(c *tls.Config) error {
+		c.InsecureSkipVerify = skip
+		return nil
+	})
+}
+
+func (G *Suite) configureTLS(configure func(config *tls.Config) error) *Suite {
+	if err := configureTLS(G.Req.Client, &G.tlsTransport, configure); err != nil {
+		G.AddError(G.Name, err.Error())
+	}
+	return G
+}
+
+// Set the TLS config of the coming request
+//
+// TLS options work with the default transport or an *http.Transport
+func (F *Frisby) SetTLSConfig(config *tls.Config) *Frisby {
+	return F.configureTLS(setTLSConfig(config))
+}
+
+// Add a client certificate and key, from PEM files, for mutual TLS
+func (F *Frisby) AddClientCert(cert_file, key_file string) *Frisby {
+	return F.configureTLS(addClientCert(cert_file, key_file))
+}
+
+// Add the PEM certificates of a CA to the trusted ones, the system pool by default
+func (F *Frisby) AddCAFile(filename string) *Frisby {
+	return F.configureTLS(addCAFile(filename))
+}
+
+// Set the pool of CA certificates trusted instead of the system pool
+func (F *Frisby) SetRootCAs(pool *x509.CertPool) *Frisby {
+	return F.configureTLS(func(c *tls.Config) error {
+		c.RootCAs = pool
+		return nil
+	})
+}
+
+// Set the server name checked against the server certificate, and sent with SNI,
+// instead of the host of the URL
+func (F *Frisby) SetServerName(name string) *Frisby {
+	return F.configureTLS(func(c *tls.Config) error {
+		c.ServerName = name
+		return nil
+	})
+}
+
+// Set the min and max TLS versions, like tls.VersionTLS12, 0 for the defaults
+func (F *Frisby) SetTLSVersions(min, max uint16) *Frisby {
+	return F.configureTLS(func(c *tls.Config) error {
+		c.MinVersion, c.MaxVersion = min, max
+		return nil
+	})
+}
+
+// Accept any server certificate, for development servers only
+func (F *Frisby) SetInsecureSkipVerify(skip bool) *Frisby {
+	return F.configureTLS(func(c *tls.Config) error {
+		c.InsecureSkipVerify = skip
+		return nil
+	})
+}
+
+// configureTLS changes the transport of the Frisby client only,
+// which is a copy of the Suite client
+func (F *Frisby) configureTLS(configure func(config *tls.Config) error) *Frisby {
+	if err := configureTLS(F.Req.Client, &F.tlsTransport, configure); err != nil {
+		F.AddError(err.Error())
+	}
+	return F
+}
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+func tlsVersionName(version uint16) string {
+	if name, ok := tlsVersionNames[version]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04X", version)
+}
+
+// expectTLS checks the TLS connection state of the response with check,
+// which returns the actual value and an error message if it failed
+func (F *Frisby) expectTLS(kind, path string, expected interface{}, check func(state *tls.ConnectionState) (interface{}, string)) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	F.countAssert()
+	if F.Resp.TLS == nil {
+		F.addAssertion(kind, path, expected, nil, "Expected a TLS connection, but the response was not sent over TLS")
+		return F
+	}
+	if kind != "tls_version" && kind != "tls_cipher" && len(F.Resp.TLS.PeerCertificates) == 0 {
+		F.addAssertion(kind, path, expected, nil, "Expected a peer certificate, but there was none")
+		return F
+	}
+	actual, err_str := check(F.Resp.TLS)
+	F.addAssertion(kind, path, expected, actual, err_str)
+	return F
+}
+
+// Checks the negotiated TLS version, like tls.VersionTLS13
+func (F *Frisby) ExpectTLSVersion(version uint16) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	expected := tlsVersionName(version)
+	return F.expectTLS("tls_version", "", expected, func(state *tls.ConnectionState) (interface{}, string) {
+		actual := tlsVersionName(state.Version)
+		if state.Version != version {
+			return actual, fmt.Sprintf("Expected TLS version %s, but got %s", expected, actual)
+		}
+		return actual, ""
+	})
+}
+
+// Checks the negotiated cipher suite, like tls.TLS_AES_128_GCM_SHA256
+func (F *Frisby) ExpectTLSCipherSuite(cipher_suite uint16) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	expected := tls.CipherSuiteName(cipher_suite)
+	return F.expectTLS("tls_cipher", "", expected, func(state *tls.ConnectionState) (interface{}, string) {
+		actual := tls.CipherSuiteName(state.CipherSuite)
+		if state.CipherSuite != cipher_suite {
+			return actual, fmt.Sprintf("Expected TLS cipher suite %s, but got %s", expected, actual)
+		}
+		return actual, ""
+	})
+}
+
+// Checks the subject of the server certificate, either its
+// common name or the full name, as in "CN=example.com,O=Example"
+func (F *Frisby) ExpectCertSubject(subject string) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	return F.expectTLS("cert_subject", "", subject, func(state *tls.ConnectionState) (interface{}, string) {
+		name := state.PeerCertificates[0].Subject
+		if name.CommonName != subject && name.String() != subject {
+			return name.String(), fmt.Sprintf("Expected certificate subject %q, but got %q", subject, name.String())
+		}
+		return name.String(), ""
+	})
+}
+
+// Checks the server certificate has the subject alternative name,
+// a DNS name, IP address, email address or URI
+func (F *Frisby) ExpectCertSAN(san string) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	return F.expectTLS("cert_san", "", san, func(state *tls.ConnectionState) (interface{}, string) {
+		cert := state.PeerCertificates[0]
+		sans := append([]string(nil), cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+		sans = append(sans, cert.EmailAddresses...)
+		for _, uri := range cert.URIs {
+			sans = append(sans, uri.String())
+		}
+		for _, name := range sans {
+			if name == san || net.ParseIP(san) != nil && net.ParseIP(san).Equal(net.ParseIP(name)) {
+				return sans, ""
+			}
+		}
+		return sans, fmt.Sprintf("Expected certificate SAN %q, but got [%s]", san, strings.Join(sans, ", "))
+	})
+}
+
+// Checks the server certificate is valid now and expires between min and max from now
+//
+// ex:  ExpectCertExpiresIn(30*24*time.Hour, 400*24*time.Hour)
+func (F *Frisby) ExpectCertExpiresIn(min, max time.Duration) *Frisby {
+	if F.T != nil {
+		F.T.Helper()
+	}
+	expected := fmt.Sprintf("%v to %v", min, max)
+	return F.expectTLS("cert_expires", "", expected, func(state *tls.ConnectionState) (interface{}, string) {
+		cert := state.PeerCertificates[0]
+		now := time.Now()
+		if now.Before(cert.NotBefore) {
+			return cert.NotBefore.String(), fmt.Sprintf("Expected certificate to be valid, but it is not valid before %v", cert.NotBefore)
+		}
+		expires_in := cert.NotAfter.Sub(now).Round(time.Second)
+		if expires_in < min || expires_in > max {
+			return expires_in.String(), fmt.Sprintf("Expected certificate to expire in %s, but it expires in %v", expected, expires_in)
+		}
+		return expires_in.String(), ""
+	})
+}
@@ -0,0 +1,263 @@
+package frisby_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+// testCert is a certificate, its key and their PEM files
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	tls      tls.Certificate
+	certFile string
+	keyFile  string
+}
+
+// newTestCert makes a certificate for name signed by parent, or a CA when parent is nil
+func newTestCert(t *testing.T, dir, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"Frisby"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signer_key := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		template.DNSNames = []string{name}
+		signer, signer_key = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signer_key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	key_der, _ := x509.MarshalECPrivateKey(key)
+
+	C := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	cert_pem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key_pem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key_der})
+	ioutil.WriteFile(C.certFile, cert_pem, 0600)
+	ioutil.WriteFile(C.keyFile, key_pem, 0600)
+	C.tls, _ = tls.X509KeyPair(cert_pem, key_pem)
+	return C
+}
+
+// newTLSServer serves TLS with a certificate for frisby.test signed by ca,
+// requiring client certificates signed by ca when mutual is true
+func newTLSServer(ca, server *testCert, mutual bool) *httptest.Server {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := ""
+		if len(r.TLS.PeerCertificates) > 0 {
+			client = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		fmt.Fprintf(w, `{"client": %q}`, client)
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{server.tls},
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	}
+	if mutual {
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.cert)
+		ts.TLS.ClientCAs = pool
+		ts.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	ts.StartTLS()
+	return ts
+}
+
+func TestTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "frisby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, dir, "Frisby CA", nil)
+	server := newTestCert(t, dir, "frisby.test", ca)
+	client := newTestCert(t, dir, "frisby-client", ca)
+
+	ts := newTLSServer(ca, server, false)
+	defer ts.Close()
+
+	F := frisby.Create("Test TLS unknown CA").Get(ts.URL).Send()
+	if F.Error() == nil {
+		t.Errorf("Expected a certificate signed by an unknown CA to fail")
+	}
+
+	G := frisby.NewSuite("Test TLS")
+	G.PrintProgressDot = false
+	G.AddCAFile(ca.certFile).SetServerName("frisby.test")
+
+	G.CreateT(t, "Test TLS").
+		Get(ts.URL).
+		Send().
+		ExpectStatus(200).
+		ExpectTLSVersion(tls.VersionTLS13).
+		ExpectCertSubject("frisby.test").
+		ExpectCertSubject("CN=frisby.test,O=Frisby").
+		ExpectCertSAN("frisby.test").
+		ExpectCertExpiresIn(22*time.Hour, 25*time.Hour)
+
+	G.CreateT(t, "Test TLS 1.2").
+		SetTLSVersions(tls.VersionTLS12, tls.VersionTLS12).
+		Get(ts.URL).
+		Send().
+		ExpectTLSVersion(tls.VersionTLS12).
+		ExpectTLSCipherSuite(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256)
+
+	F = G.Create("Test TLS wrong server name").SetServerName("other.test").Get(ts.URL).Send()
+	if F.Error() == nil {
+		t.Errorf("Expected a certificate for another name to fail")
+	}
+
+	frisby.CreateT(t, "Test TLS insecure").
+		SetInsecureSkipVerify(true).
+		Get(ts.URL).
+		Send().
+		ExpectStatus(200)
+
+	mts := newTLSServer(ca, server, true)
+	defer mts.Close()
+
+	F = G.Create("Test mTLS without cert").Get(mts.URL).Send()
+	if F.Error() == nil {
+		t.Errorf("Expected mutual TLS without a client certificate to fail")
+	}
+
+	G.CreateT(t, "Test mTLS").
+		AddClientCert(client.certFile, client.keyFile).
+		Get(mts.URL).
+		Send().
+		ExpectStatus(200).
+		ExpectJson("client", "frisby-client")
+}
+
+func TestTLSTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "frisby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, dir, "Frisby CA", nil)
+	server := newTestCert(t, dir, "frisby.test", ca)
+
+	ts := newTLSServer(ca, server, false)
+	defer ts.Close()
+
+	transport := &http.Transport{}
+	client := &http.Client{Transport: transport}
+
+	G := frisby.NewSuite("Test TLS transport")
+	G.PrintProgressDot = false
+	G.SetClient(client).AddCAFile(ca.certFile)
+	owned := G.Req.Client.Transport
+	G.SetServerName("frisby.test")
+
+	// Clone() may set up HTTP/2 on the original, but never the options
+	if client.Transport != transport || transport.TLSClientConfig != nil &&
+		(transport.TLSClientConfig.RootCAs != nil || transport.TLSClientConfig.ServerName != "") {
+		t.Errorf("Expected the caller's client and transport to be unchanged")
+	}
+	if owned == transport || G.Req.Client.Transport != owned {
+		t.Errorf("Expected the transport to be cloned once for the Suite")
+	}
+
+	F := G.CreateT(t, "Test TLS transport Frisby").SetTLSVersions(tls.VersionTLS12, tls.VersionTLS12)
+	cloned := F.Req.Client.Transport
+	F.SetInsecureSkipVerify(false)
+	if cloned == owned || F.Req.Client.Transport != cloned {
+		t.Errorf("Expected the transport to be cloned once for the Frisby")
+	}
+	F.Get(ts.URL).Send().ExpectTLSVersion(tls.VersionTLS12)
+
+	G.CreateT(t, "Test TLS transport Suite").
+		Get(ts.URL).
+		Send().
+		ExpectTLSVersion(tls.VersionTLS13)
+}
+
+func TestExpectTLSFailures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "frisby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, dir, "Frisby CA", nil)
+	server := newTestCert(t, dir, "frisby.test", ca)
+	ts := newTLSServer(ca, server, false)
+	defer ts.Close()
+
+	F := frisby.Create("Test ExpectTLS failures").
+		SetInsecureSkipVerify(true).
+		SetTLSVersions(tls.VersionTLS12, tls.VersionTLS12).
+		Get(ts.URL).
+		Send().
+		ExpectTLSVersion(tls.VersionTLS13).
+		ExpectTLSCipherSuite(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256).
+		ExpectCertSubject("example.com").
+		ExpectCertSAN("127.0.0.1").
+		ExpectCertExpiresIn(30*24*time.Hour, 90*24*time.Hour)
+
+	expected := []string{
+		"Expected TLS version TLS 1.3, but got TLS 1.2",
+		"Expected TLS cipher suite TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, but got TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		`Expected certificate subject "example.com", but got "CN=frisby.test,O=Frisby"`,
+		`Expected certificate SAN "127.0.0.1", but got [frisby.test]`,
+		"Expected certificate to expire in 720h0m0s to 2160h0m0s, but it expires in ",
+	}
+	if len(F.Errs) != len(expected) {
+		t.Fatalf("Expected %d errors, but got %v", len(expected), F.Errs)
+	}
+	for i, err := range F.Errs {
+		if !strings.HasPrefix(err.Error(), expected[i]) {
+			t.Errorf("Expected error %q, but got %q", expected[i], err)
+		}
+	}
+
+	hts := newEchoServer()
+	defer hts.Close()
+	F = frisby.Create("Test ExpectTLS without TLS").Get(hts.URL).Send().ExpectTLSVersion(tls.VersionTLS13)
+	if F.Error() == nil || F.Error().Error() != "Expected a TLS connection, but the response was not sent over TLS" {
+		t.Errorf("Expected the TLS expectation to fail without TLS, but got %v", F.Error())
+	}
+
+	F = frisby.Create("Test TLS options with a handler").SetHandler(http.NotFoundHandler()).SetServerName("frisby.test")
+	if F.Error() == nil || !strings.HasPrefix(F.Error().Error(), "TLS options need an *http.Transport") {
+		t.Errorf("Expected TLS options to fail with a handler transport, but got %v", F.Error())
+	}
+}
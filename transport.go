@@ -0,0 +1,214 @@
+package frisby
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+)
+
+// roundTrip is a request sent through a recorder, along with
+// copies of the request and response bodies
+type roundTrip struct {
+	start   time.Time
+	req     *http.Request
+	reqBody []byte
+	resp    *http.Response
+	body    []byte
+	err     error
+
+	// TTFB and Transfer, as seen by the recorder
+	timing Timing
+}
+
+// recorder is an http.RoundTripper which keeps every request sent
+// through it, like redirects and answers to challenges, in order
+type recorder struct {
+	next http.RoundTripper
+
+	trips []*roundTrip
+}
+
+func (R *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	trip := &roundTrip{start: time.Now(), req: req}
+	R.trips = append(R.trips, trip)
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			trip.err = err
+			return nil, err
+		}
+		trip.reqBody = body
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	next := R.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	trip.timing.TTFB = time.Since(trip.start)
+	if err != nil {
+		trip.err = err
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	trip.timing.Total = time.Since(trip.start)
+	trip.timing.Transfer = trip.timing.Total - trip.timing.TTFB
+	if err != nil {
+		trip.err = err
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	trip.resp, trip.body = resp, body
+	return resp, nil
+}
+
+// last returns the last round trip, nil when no request was sent
+func (R *recorder) last() *roundTrip {
+	if len(R.trips) == 0 {
+		return nil
+	}
+	return R.trips[len(R.trips)-1]
+}
+
+// record installs a recorder on the client of the Frisby object
+// and returns it along with a function restoring the client
+func (F *Frisby) record() (*recorder, func()) {
+	var rec *recorder
+	restore := F.wrapTransport(func(next http.RoundTripper) http.RoundTripper {
+		rec = &recorder{next: next}
+		return rec
+	})
+	return rec, restore
+}
+
+// wrapTransport replaces the transport of the Frisby client with
+// the one returned by wrap, and returns a function restoring it
+func (F *Frisby) wrapTransport(wrap func(next http.RoundTripper) http.RoundTripper) func() {
+	client := F.Req.Client
+	transport := client.Transport
+	client.Transport = wrap(transport)
+	return func() {
+		client.Transport = transport
+	}
+}
+
+// proxy sends the requests of the Frisby client through the Proxy URL,
+// with a copy of its transport, and returns a function restoring it
+func (F *Frisby) proxy() (func(), error) {
+	proxy_url, err := url.Parse(F.Req.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	var transport *http.Transport
+	switch t := F.Req.Client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, fmt.Errorf("a Proxy needs an *http.Transport, but the client uses a %T", t)
+	}
+	transport.Proxy = http.ProxyURL(proxy_url)
+	restore := F.wrapTransport(func(http.RoundTripper) http.RoundTripper {
+		return transport
+	})
+	return func() {
+		restore()
+		transport.CloseIdleConnections()
+	}, nil
+}
+
+// handlerTransport is an http.RoundTripper serving every request
+// with an http.Handler in the same process
+type handlerTransport struct {
+	handler http.Handler
+}
+
+// HandlerTransport returns an http.RoundTripper which calls handler
+// directly instead of opening a connection
+//
+// The URL host of requests is kept as the Host of the handled
+// request, so any host can be used, ex: 'http://api.test/users'
+func HandlerTransport(handler http.Handler) http.RoundTripper {
+	return &handlerTransport{handler: handler}
+}
+
+func (H *handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the handler gets the request as a server would
+	sreq := req.Clone(req.Context())
+	sreq.RequestURI = req.URL.RequestURI()
+	sreq.RemoteAddr = "192.0.2.1:1234"
+	if sreq.Host == "" {
+		sreq.Host = req.URL.Host
+	}
+	if sreq.Body == nil {
+		sreq.Body = http.NoBody
+	}
+
+	w := httptest.NewRecorder()
+	H.handler.ServeHTTP(w, sreq)
+
+	resp := w.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// copyClient returns a shallow copy of client, or a new http.Client when nil,
+// so later options never change the client of the caller
+func copyClient(client *http.Client) *http.Client {
+	if client == nil {
+		return new(http.Client)
+	}
+	copied := *client
+	return &copied
+}
+
+// Set the http.Client used to send requests, nil for a new http.Client
+//
+// Frisby objects created after this call get a copy of it
+func (G *Suite) SetClient(client *http.Client) *Suite {
+	G.Req.Client = copyClient(client)
+	return G
+}
+
+// Set the http.RoundTripper used to send requests, nil for http.DefaultTransport
+//
+// Frisby objects created after this call use it
+func (G *Suite) SetTransport(transport http.RoundTripper) *Suite {
+	G.Req.Client.Transport = transport
+	return G
+}
+
+// Send requests to handler in the same process, without network
+//
+// Frisby objects created after this call use it
+func (G *Suite) SetHandler(handler http.Handler) *Suite {
+	return G.SetTransport(HandlerTransport(handler))
+}
+
+// Set the http.Client used to send the coming request, nil for a new http.Client
+//
+// The request is sent with a copy of it
+func (F *Frisby) SetClient(client *http.Client) *Frisby {
+	F.Req.Client = copyClient(client)
+	return F
+}
+
+// Set the http.RoundTripper used to send the coming request, nil for http.DefaultTransport
+func (F *Frisby) SetTransport(transport http.RoundTripper) *Frisby {
+	F.Req.Client.Transport = transport
+	return F
+}
+
+// Send the coming request to handler in the same process, without network
+func (F *Frisby) SetHandler(handler http.Handler) *Frisby {
+	return F.SetTransport(HandlerTransport(handler))
+}
@@ -0,0 +1,103 @@
+package frisby_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/verdverm/frisby"
+)
+
+func newTestHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"method": r.Method,
+			"host":   r.Host,
+			"uri":    r.RequestURI,
+			"auth":   r.Header.Get("Authorization"),
+		})
+	})
+	return mux
+}
+
+func TestSetHandler(t *testing.T) {
+	frisby.CreateT(t, "Test SetHandler").
+		SetHandler(newTestHandler()).
+		Post("http://api.test/users/1").
+		SetParam("q", "a b").
+		SetHeader("Authorization", "Bearer token").
+		Send().
+		ExpectStatus(http.StatusCreated).
+		ExpectHeader("Content-Type", "application/json").
+		ExpectJson("method", "POST").
+		ExpectJson("host", "api.test").
+		ExpectJson("uri", "/users/1?q=a+b").
+		ExpectJson("auth", "Bearer token")
+
+	F := frisby.Create("Test SetHandler not found").
+		SetHandler(newTestHandler()).
+		Get("http://api.test/missing").
+		Send().
+		ExpectStatus(http.StatusNotFound)
+	if len(F.Errs) != 0 {
+		t.Errorf("Expected no errors, but got %v", F.Errs)
+	}
+}
+
+func TestGlobalSetTransport(t *testing.T) {
+	ts := httptest.NewServer(newTestHandler())
+	defer ts.Close()
+
+	defer frisby.Global.SetTransport(nil)
+
+	frisby.Global.SetHandler(newTestHandler())
+	frisby.CreateT(t, "Test Global SetHandler").
+		Get("http://api.test/users/2").
+		Send().
+		ExpectStatus(http.StatusCreated).
+		ExpectJson("uri", "/users/2")
+
+	frisby.Global.SetTransport(ts.Client().Transport)
+	frisby.CreateT(t, "Test Global SetTransport").
+		Get(ts.URL+"/users/3").
+		Send().
+		ExpectStatus(http.StatusCreated).
+		ExpectJson("uri", "/users/3")
+}
+
+func TestSetClient(t *testing.T) {
+	client := &http.Client{Timeout: time.Minute}
+
+	G := frisby.NewSuite("Test SetClient")
+	G.PrintProgressDot = false
+	G.SetClient(client).SetHandler(newTestHandler())
+	G.CreateT(t, "Test SetClient suite").
+		Get("http://api.test/users/1").
+		Send().
+		ExpectStatus(http.StatusCreated)
+
+	frisby.CreateT(t, "Test SetClient").
+		SetClient(client).
+		SetHandler(newTestHandler()).
+		SetSession(frisby.NewSession()).
+		Get("http://api.test/users/2").
+		Send().
+		ExpectStatus(http.StatusCreated)
+
+	if client.Transport != nil || client.Jar != nil {
+		t.Errorf("Expected the client to be unchanged, but got %+v", client)
+	}
+
+	G.SetClient(nil).SetHandler(newTestHandler())
+	G.CreateT(t, "Test SetClient nil").
+		SetClient(nil).
+		SetHandler(newTestHandler()).
+		Get("http://api.test/users/3").
+		Send().
+		ExpectStatus(http.StatusCreated)
+}
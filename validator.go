@@ -0,0 +1,29 @@
+package frisby
+
+import (
+	"net/http"
+)
+
+// Validator checks a request, as it was sent, and the response of the Frisby object
+//
+// Validate() is called by every Send() which got a response, and records its
+// results with Frisby.Assert(). The openapi package validates against an OpenAPI 3 document.
+type Validator interface {
+	Validate(F *Frisby, req *http.Request, body []byte)
+}
+
+// Set the Validator which requests and responses are checked by
+//
+// Frisby objects created after this call validate every Send()
+func (G *Suite) SetValidator(validator Validator) *Suite {
+	G.Validator = validator
+	return G
+}
+
+// Set the Validator which the coming request and response are checked by
+//
+// Set it to nil to skip the validation of the Suite
+func (F *Frisby) SetValidator(validator Validator) *Frisby {
+	F.Validator = validator
+	return F
+}